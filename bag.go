@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+)
+
+// Trainer record holding the bag
+const bagRecord = "bag"
+
+// What a trainer starts out with
+var starterBag = bag{
+	"poke-ball":   20,
+	"great-ball":  10,
+	"ultra-ball":  5,
+	"master-ball": 1,
+}
+
+// Balls in the order the bag lists them
+var ballOrder = []string{"poke-ball", "great-ball", "ultra-ball", "master-ball"}
+
+// Item counts by PokeAPI item name.
+type bag map[string]int
+
+// The current trainer's bag, the starter bag if they never had one.
+func (cfg *config) bag() (bag, error) {
+	var b bag
+	found, err := cfg.storage.Record(bagRecord, &b)
+	if err != nil {
+		return nil, err
+	}
+	if !found || b == nil {
+		return maps.Clone(starterBag), nil
+	}
+	return b, nil
+}
+
+// Takes one of an item out of the bag.
+func (cfg *config) takeItem(item string) error {
+	b, err := cfg.bag()
+	if err != nil {
+		return err
+	}
+	if b[item] < 1 {
+		return fmt.Errorf("You have no %s left! See 'bag'", toDisplay(item))
+	}
+	b[item]--
+	if b[item] == 0 {
+		delete(b, item)
+	}
+	return cfg.storage.SetRecord(bagRecord, b)
+}
+
+// Ball names as typed, "great" and "Great Ball" both mean great-ball.
+func ballName(name string) string {
+	name = toSlug(name)
+	if !strings.HasSuffix(name, "-ball") {
+		name += "-ball"
+	}
+	return name
+}
+
+// Items use knows about, by PokeAPI name. Each changes the pokemon it is used on
+// and says what happened, the caller stores the change and takes the item.
+var itemEffects = map[string]func(cfg *config, c *Caught) (string, error){
+	"rare-candy": rareCandy,
+	"hp-up":      vitamin("hp"),
+	"protein":    vitamin("attack"),
+	"iron":       vitamin("defense"),
+	"calcium":    vitamin("special-attack"),
+	"zinc":       vitamin("special-defense"),
+	"carbos":     vitamin("speed"),
+}
+
+// Vitamins add 10 EVs to a stat, as long as it has less than 100 and the pokemon under 510 in total.
+func vitamin(stat string) func(cfg *config, c *Caught) (string, error) {
+	return func(_ *config, c *Caught) (string, error) {
+		ev := c.EVs.Stat(stat)
+		total := c.EVs.HP + c.EVs.Attack + c.EVs.Defense + c.EVs.SpecialAttack + c.EVs.SpecialDefense + c.EVs.Speed
+		if *ev >= 100 || total >= 510 {
+			return "", fmt.Errorf("It won't have any effect on %s's %s", c.Name(), stat)
+		}
+		*ev += min(10, 100-*ev, 510-total)
+		return fmt.Sprintf("%s's %s rose!", c.Name(), stat), nil
+	}
+}
+
+// Rare candy gives exactly the experience the next level takes.
+func rareCandy(cfg *config, c *Caught) (string, error) {
+	if c.Level >= maxLevel {
+		return "", fmt.Errorf("It won't have any effect on %s, it's level %d already", c.Name(), maxLevel)
+	}
+	rate, err := fetchGrowthRate(cfg, c.Pokemon)
+	if err != nil {
+		return "", err
+	}
+	level := max(c.Level, 1)
+	ups, err := cfg.gainExperience(c, rate.experienceAt(level+1)-max(c.Experience, rate.experienceAt(level)))
+	if err != nil {
+		return "", err
+	}
+	messages := make([]string, 0, len(ups))
+	for _, up := range ups {
+		messages = append(messages, up.Text())
+	}
+	return strings.Join(messages, "\n"), nil
+}
+
+// Shows the bag, or adds items to it for debugging
+func commandBag(cfg *config, args []string, _ cmdFlags) (any, error) {
+	b, err := cfg.bag()
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return newBagList(b), nil
+	}
+	if args[0] != "add" || len(args) < 2 {
+		return nil, errUsage
+	}
+
+	item := toSlug(args[1])
+	count := 1
+	if len(args) > 2 {
+		if count, err = strconv.Atoi(args[2]); err != nil || count < 1 || count > 999 {
+			return nil, errors.New("Count must be 1 to 999")
+		}
+	}
+	//Only real items go in the bag
+	if _, err = fetch(cfg.cache, cfg.baseURL+"/item/"+item); errors.Is(err, errNotFound) {
+		return nil, fmt.Errorf("There is no item called %s", args[1])
+	} else if err != nil {
+		return nil, fmt.Errorf("Error Fetching URL: %w", err)
+	}
+
+	b[item] = min(b[item]+count, 999)
+	if err = cfg.storage.SetRecord(bagRecord, b); err != nil {
+		return nil, err
+	}
+	note(fmt.Sprintf("bag add is for debugging, the %s cost nothing", toDisplay(item)))
+	return newBagList(b), nil
+}
+
+// Uses an item from the bag on a caught pokemon
+func commandUse(cfg *config, args []string, _ cmdFlags) (any, error) {
+	if len(args) < 2 {
+		return nil, errUsage
+	}
+	item := toSlug(args[0])
+	effect, known := itemEffects[item]
+	if !known {
+		if _, isBall := ballModifiers[item]; isBall {
+			return nil, fmt.Errorf("Balls are thrown, use 'catch <pokemon> --ball %s'", strings.TrimSuffix(item, "-ball"))
+		}
+		return nil, fmt.Errorf("%s can't be used here", toDisplay(item))
+	}
+
+	b, err := cfg.bag()
+	if err != nil {
+		return nil, err
+	}
+	if b[item] < 1 {
+		return nil, fmt.Errorf("You have no %s left! See 'bag'", toDisplay(item))
+	}
+	caught, err := findCaught(cfg.storage, args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := effect(cfg, caught)
+	if err != nil {
+		return nil, err
+	}
+	if err = cfg.storage.Update(caught); err != nil {
+		return nil, err
+	}
+	if err = cfg.takeItem(item); err != nil {
+		return nil, err
+	}
+	return useResult{Item: item, ID: caught.ID, Message: message, Left: b[item] - 1}, nil
+}
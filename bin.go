@@ -0,0 +1,139 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Trainer record holding released pokemon
+const binRecord = "bin"
+
+// Days released pokemon are kept when the profile doesn't say otherwise
+const defaultBinDays = 30
+
+// A released pokemon waiting in the recycle bin.
+type binEntry struct {
+	Caught     *Caught   `json:"caught"`
+	ReleasedAt time.Time `json:"released_at"`
+}
+
+// Days left before the entry is gone for good.
+func (e binEntry) daysLeft(keep int, now time.Time) int {
+	left := e.ReleasedAt.AddDate(0, 0, keep).Sub(now)
+	return int((left + 24*time.Hour - 1) / (24 * time.Hour))
+}
+
+// How long the current profile keeps released pokemon, in days.
+func (cfg *config) binDays() (int, error) {
+	settings, err := cfg.settings()
+	return cmp.Or(settings.BinDays, defaultBinDays), err
+}
+
+// The recycle bin, oldest release first.
+func (cfg *config) bin() ([]binEntry, error) {
+	var bin []binEntry
+	_, err := cfg.storage.Record(binRecord, &bin)
+	return bin, err
+}
+
+// Moves a released pokemon into the recycle bin.
+func (cfg *config) throwAway(c *Caught) error {
+	bin, err := cfg.bin()
+	if err != nil {
+		return err
+	}
+	return cfg.storage.SetRecord(binRecord, append(bin, binEntry{Caught: c, ReleasedAt: time.Now()}))
+}
+
+// Drops pokemon that have been in the bin longer than the profile keeps them.
+func (cfg *config) emptyExpired() error {
+	bin, err := cfg.bin()
+	if err != nil || len(bin) == 0 {
+		return err
+	}
+	keep, err := cfg.binDays()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	kept := bin[:0]
+	for _, entry := range bin {
+		if entry.daysLeft(keep, now) > 0 {
+			kept = append(kept, entry)
+		}
+	}
+	if len(kept) == len(bin) {
+		return nil
+	}
+	return cfg.storage.SetRecord(binRecord, kept)
+}
+
+// Lists, restores or empties released pokemon
+func commandBin(cfg *config, args []string, flags cmdFlags) (any, error) {
+	bin, err := cfg.bin()
+	if err != nil {
+		return nil, err
+	}
+	keep, err := cfg.binDays()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) == 0 {
+		list := binList{Days: keep, Pokemon: []binListEntry{}}
+		now := time.Now()
+		for _, entry := range bin {
+			list.Pokemon = append(list.Pokemon, binListEntry{
+				pokedexEntry: newPokedexEntry(entry.Caught),
+				ReleasedAt:   entry.ReleasedAt,
+				DaysLeft:     entry.daysLeft(keep, now),
+			})
+		}
+		return list, nil
+	}
+
+	switch args[0] {
+	case "restore":
+		if len(args) < 2 {
+			return nil, errUsage
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return nil, errUsage
+		}
+		for i, entry := range bin {
+			if entry.Caught.ID != id {
+				continue
+			}
+			caught := entry.Caught
+			if err = checkNickname(cfg.storage, caught.ID, caught.Nickname); err != nil {
+				return nil, err
+			}
+			placeNew(cfg.storage, caught)
+			if err = cfg.storage.Restore(caught); err != nil {
+				return nil, err
+			}
+			if err = cfg.storage.SetRecord(binRecord, append(bin[:i], bin[i+1:]...)); err != nil {
+				return nil, err
+			}
+			return newMoveResult(caught), nil
+		}
+		return nil, fmt.Errorf("No pokemon with ID %d in the bin", id)
+	case "empty":
+		if len(bin) == 0 {
+			return nil, errors.New("The bin is already empty!")
+		}
+		if !flags.has("yes") && !confirm(fmt.Sprintf("Let go of %d pokemon for good?", len(bin))) {
+			return nil, errors.New("Kept the bin as it was")
+		}
+		if err = cfg.storage.SetRecord(binRecord, []binEntry{}); err != nil {
+			return nil, err
+		}
+		return binList{Days: keep, Pokemon: []binListEntry{}}, nil
+	}
+	return nil, errUsage
+}
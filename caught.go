@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Argument help shared by commands that take a caught pokemon
+const caughtRefHelp = "ID or nickname of a caught pokemon, see pokedex"
+
+// Whether ref is a catch's ID, nickname or species, used to filter the pokedex.
+func matchesCaught(c *Caught, ref string) bool {
+	return strconv.Itoa(c.ID) == ref || strings.EqualFold(c.Nickname, ref) || c.Pokemon.Name == ref
+}
+
+// Finds a caught pokemon by ID or nickname. A species name also works while only one of it was caught.
+func findCaught(s Storage, ref string) (*Caught, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		if caught, exists := s.Get(id); exists {
+			return caught, nil
+		}
+		return nil, errNotCaught
+	}
+
+	named := s.Query(func(c *Caught) bool { return strings.EqualFold(c.Nickname, ref) })
+	if len(named) == 1 {
+		return named[0], nil
+	}
+
+	species := s.Query(func(c *Caught) bool { return c.Pokemon.Name == ref })
+	switch len(species) {
+	case 0:
+		return nil, errNotCaught
+	case 1:
+		return species[0], nil
+	}
+	ids := make([]string, 0, len(species))
+	for _, c := range species {
+		ids = append(ids, strconv.Itoa(c.ID))
+	}
+	return nil, fmt.Errorf("You have caught more than one %s, use an ID: %s", ref, strings.Join(ids, ", "))
+}
+
+// Nicknames have to be usable as references, so they must be unique and not look like an ID.
+// self is the ID of the catch being named, which may keep its own nickname, 0 for a new one.
+func checkNickname(s Storage, self int, nickname string) error {
+	if nickname == "" {
+		return nil
+	}
+	if _, err := strconv.Atoi(nickname); err == nil {
+		return errors.New("A nickname can't be a number, those are IDs")
+	}
+	taken := s.Query(func(c *Caught) bool { return c.ID != self && strings.EqualFold(c.Nickname, nickname) })
+	if len(taken) > 0 {
+		return fmt.Errorf("#%d is already called %s", taken[0].ID, taken[0].Nickname)
+	}
+	return nil
+}
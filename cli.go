@@ -0,0 +1,386 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Crimsonchamp/pokedexcli/internal/output"
+	"github.com/Crimsonchamp/pokedexcli/internal/pokecache"
+	"github.com/Crimsonchamp/pokedexcli/storage"
+)
+
+// Default PokeAPI root, overridden with --base-url
+const defaultBaseURL = "https://pokeapi.co/api/v2"
+
+var errUnknownCommand = errors.New("Sorry I don't understand, type 'help' for commands")
+
+// Returned by the exit command to end the session through the normal shutdown path.
+var errExit = errors.New("exit")
+
+// Session state handed to every command, filled from the global flags.
+type config struct {
+	cache           *pokecache.Cache
+	storage         Storage
+	history         *journal
+	storageBackend  string
+	baseURL         string
+	cacheDir        string
+	saveFile        string
+	output          output.Format
+	rng             *rand.Rand
+	outputSet       bool
+	profile         string
+	currentLocation *Location
+	currentArea     string
+	// Game version encounters come from, empty for all of them
+	gameVersion string
+}
+
+// Parses the global flags, everything after them is a one-shot command. Errors are already printed.
+func parseFlags(args []string) (*config, []string, error) {
+	cfg := &config{}
+
+	fs := flag.NewFlagSet("pokedexcli", flag.ContinueOnError)
+	fs.StringVar(&cfg.baseURL, "base-url", defaultBaseURL, "PokeAPI base URL")
+	fs.StringVar(&cfg.cacheDir, "cache-dir", defaultCacheDir(), "directory the response cache is kept in between runs, empty to disable")
+	fs.StringVar(&cfg.storageBackend, "storage", "file", "storage backend for caught pokemon: "+strings.Join(storage.Names(), ", "))
+	fs.StringVar(&cfg.profile, "profile", lastProfile(), "trainer profile to play as, each has its own box, stats and settings")
+	fs.StringVar(&cfg.saveFile, "save", "", "save file of the file backend, instead of the profile's own")
+	format := fs.String("output", string(output.Text), "output format: "+formatNames())
+	fs.StringVar(&cfg.gameVersion, "game-version", "", "game version wild pokemon come from, such as red or diamond (all of them if not given)")
+	fs.BoolVar(&acceptUnsealed, "accept-unsealed", false, "load a save that lost its checksum or encryption, after putting back an old copy yourself")
+	seed := fs.Int64("seed", 0, "seed for catches and encounters, the same seed and commands give the same results (random if not given)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pokedexcli [flags] [command [args...]]")
+		fmt.Fprintln(fs.Output(), "\nWithout a command the interactive Pokedex starts, 'pokedexcli help' lists commands.")
+		fmt.Fprintln(fs.Output(), "\nFlags:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	seeded, profileSet := false, false
+	fs.Visit(func(f *flag.Flag) {
+		cfg.outputSet = cfg.outputSet || f.Name == "output"
+		seeded = seeded || f.Name == "seed"
+		profileSet = profileSet || f.Name == "profile"
+	})
+	if !seeded {
+		*seed = time.Now().UnixNano()
+	}
+	cfg.rng = rand.New(rand.NewSource(*seed))
+	if !profileNamePattern.MatchString(cfg.profile) {
+		err := fmt.Errorf("invalid profile name %q", cfg.profile)
+		fmt.Fprintln(fs.Output(), err)
+		return nil, nil, err
+	}
+	//A mistyped -profile would start a new trainer, so like 'profile switch' only known ones are taken
+	if profileSet && cfg.saveFile == "" && cfg.profile != defaultProfile {
+		names, err := listProfiles("")
+		if err == nil && !slices.Contains(names, cfg.profile) {
+			err = fmt.Errorf("No profile named %s, create it with 'profile new %s'", cfg.profile, cfg.profile)
+		}
+		if err != nil {
+			fmt.Fprintln(fs.Output(), err)
+			return nil, nil, err
+		}
+	}
+	cfg.output = output.Format(*format)
+	if !slices.Contains(output.Formats, cfg.output) {
+		err := fmt.Errorf("unsupported output format %q, use one of: %s", cfg.output, formatNames())
+		fmt.Fprintln(fs.Output(), err)
+		return nil, nil, err
+	}
+	cfg.baseURL = strings.TrimSuffix(cfg.baseURL, "/")
+	cfg.gameVersion = toSlug(cfg.gameVersion)
+	return cfg, fs.Args(), nil
+}
+
+func formatNames() string {
+	names := make([]string, 0, len(output.Formats))
+	for _, format := range output.Formats {
+		names = append(names, string(format))
+	}
+	return strings.Join(names, ", ")
+}
+
+// Cache directory under the user's cache dir, empty if there is none.
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pokedexcli")
+}
+
+// Loads the persisted cache and, unless the command doesn't need it, the profile named by the flags.
+func (cfg *config) loadState(words []string) error {
+	cfg.cache = pokecache.NewCache(5 * time.Minute)
+	if cfg.cacheDir != "" {
+		//The cache only saves fetching again, a damaged one is started over
+		if err := cfg.cache.Load(filepath.Join(cfg.cacheDir, "cache.json")); err != nil {
+			note(fmt.Sprintf("Couldn't read the cache, starting with an empty one: %v", err))
+		}
+	}
+
+	if cfg.gameVersion != "" {
+		if err := checkGameVersion(cfg, cfg.gameVersion); err != nil {
+			return fmt.Errorf("-game-version: %w", err)
+		}
+	}
+
+	if withoutProfile(words) {
+		return nil
+	}
+	if err := cfg.openProfile(cfg.profile); err != nil {
+		return fmt.Errorf("opening profile %s: %w", cfg.profile, err)
+	}
+	return nil
+}
+
+// One-shot commands that run without opening the profile, so they see its save file
+// as it is on disk: save verify has to report a damaged save rather than fail to load it.
+func withoutProfile(words []string) bool {
+	if len(words) < 2 {
+		return false
+	}
+	cmd, exists := lookupCommand(getCommandMap(), words[0])
+	return exists && cmd.name == "save" && words[1] == "verify"
+}
+
+// Shared shutdown path for exit, EOF, SIGINT at the prompt and SIGTERM.
+// Saves the box first since it matters most, then persists the cache and stops its reaper.
+// Every step runs even if an earlier one failed, the first error is returned.
+func (cfg *config) shutdown() error {
+	var errs []error
+
+	if cfg.storage != nil {
+		if err := cfg.storage.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("closing storage: %w", err))
+		}
+	}
+	if cfg.cacheDir != "" {
+		if err := os.MkdirAll(cfg.cacheDir, 0o755); err != nil {
+			errs = append(errs, fmt.Errorf("saving cache: %w", err))
+		} else if err := cfg.cache.Save(filepath.Join(cfg.cacheDir, "cache.json")); err != nil {
+			errs = append(errs, fmt.Errorf("saving cache: %w", err))
+		}
+	}
+	cfg.cache.Close()
+
+	return errors.Join(errs...)
+}
+
+// Runs one command line against the registry, shared by the REPL and one-shot mode.
+func runCommand(cfg *config, commands map[string]cliCommand, words []string) (any, error) {
+	cmd, exists := lookupCommand(commands, words[0])
+	if !exists {
+		return suggestCommand(cfg, commands, words)
+	}
+
+	args, flags, err := parseCommandArgs(cmd, words[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	//Whatever the command changes can be taken back with undo,
+	//commands run without a profile have nothing to take back
+	var result any
+	if history := cfg.history; history != nil {
+		history.begin(words)
+		result, err = cmd.callback(cfg, args, flags)
+		if endErr := history.end(); endErr != nil && err == nil {
+			err = endErr
+		}
+	} else {
+		result, err = cmd.callback(cfg, args, flags)
+	}
+	if errors.Is(err, errUsage) {
+		return nil, fmt.Errorf("Error, Incorrect Format - Use: %s", cmd.usage)
+	}
+	return result, err
+}
+
+// Flags given to a command, by name without the dashes. On/off flags are "true" when set.
+type cmdFlags map[string]string
+
+func (f cmdFlags) get(name string) string {
+	return f[name]
+}
+
+func (f cmdFlags) has(name string) bool {
+	_, set := f[name]
+	return set
+}
+
+// Splits command arguments into positional ones and the flags the command declares.
+// Flags may go anywhere, as --name value, --name=value, or just --name for on/off flags.
+func parseCommandArgs(cmd cliCommand, words []string) ([]string, cmdFlags, error) {
+	var args []string
+	flags := cmdFlags{}
+
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if word == "--" {
+			args = append(args, words[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(word, "-") || word == "-" {
+			args = append(args, word)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(word, "-"), "=")
+		idx := slices.IndexFunc(cmd.flags, func(f cliFlag) bool { return f.name == name })
+		if idx < 0 {
+			return nil, nil, fmt.Errorf("Unknown flag --%s, see 'help %s'", name, cmd.name)
+		}
+
+		switch {
+		case cmd.flags[idx].value == "":
+			if hasValue {
+				return nil, nil, fmt.Errorf("Flag --%s does not take a value", name)
+			}
+			value = "true"
+		case !hasValue:
+			if i+1 >= len(words) {
+				return nil, nil, fmt.Errorf("Flag --%s needs a %s", name, cmd.flags[idx].value)
+			}
+			i++
+			value = words[i]
+		}
+		flags[name] = value
+	}
+	return args, flags, nil
+}
+
+// Results that can report a failure, such as a save that doesn't verify. They are
+// still shown, and the failure is the command's error.
+type failedResult interface {
+	failed() error
+}
+
+// Runs a command line and renders its result on stdout, errors other than errExit go to stderr.
+func execute(cfg *config, commands map[string]cliCommand, words []string) error {
+	result, err := runCommand(cfg, commands, words)
+	if err == nil {
+		err = output.Render(os.Stdout, cfg.output, result)
+	}
+	if f, ok := result.(failedResult); ok && err == nil {
+		err = f.failed()
+	}
+	if err != nil && !errors.Is(err, errExit) {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	return err
+}
+
+// Prints progress and status messages on stderr, keeping stdout for results.
+func note(msg string) {
+	fmt.Fprintln(os.Stderr, msg)
+}
+
+// Runs a single command from the shell and returns the exit code.
+// A signal lets the command finish, without waiting on any question it asks, then shuts down.
+func runOnce(cfg *config, words []string) int {
+	err := execute(cfg, getCommandMap(), words)
+	if shutdownErr := cfg.shutdown(); shutdownErr != nil {
+		fmt.Fprintln(os.Stderr, shutdownErr)
+		return 1
+	}
+	select {
+	case <-interrupted():
+		return 130
+	default:
+	}
+	if err != nil && !errors.Is(err, errExit) {
+		return 1
+	}
+	return 0
+}
+
+// Interactive prompt loop, returns once the session should shut down.
+func repl(cfg *config) {
+	fmt.Println("Welcome to a Pokedex!\nType 'help' if you need guidance!")
+
+	commands := getCommandMap()
+
+	for {
+		fmt.Print("pokedex > ")
+
+		select {
+		case line, ok := <-stdinLines():
+			//EOF, e.g. ctrl-d or the end of piped input
+			if !ok {
+				fmt.Println()
+				return
+			}
+
+			words := strings.Fields(line)
+			if len(words) == 0 {
+				continue
+			}
+
+			//Parse Input to command map, trigger input's callback command.
+			if err := execute(cfg, commands, words); errors.Is(err, errExit) {
+				return
+			}
+		case <-interrupted():
+			fmt.Println()
+			return
+		}
+	}
+}
+
+// Closed at the first SIGINT or SIGTERM, shared by one-shot mode, the prompt and every question.
+// Questions give up on it and a running command finishes, then the session shuts down as
+// usual. A second signal quits right away.
+var interrupted = sync.OnceValue(func() <-chan struct{} {
+	done := make(chan struct{})
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		close(done)
+		<-signals
+		os.Exit(130)
+	}()
+	return done
+})
+
+// A line typed in answer to a question, false at EOF or once interrupted.
+func readAnswer() (string, bool) {
+	select {
+	case line, ok := <-stdinLines():
+		return line, ok
+	case <-interrupted():
+		fmt.Fprintln(os.Stderr)
+		return "", false
+	}
+}
+
+// Lines typed on stdin, read in the background so the prompt can wait on signals at the same time.
+// Closed at EOF. Shared by the prompt and yes/no questions.
+var stdinLines = sync.OnceValue(func() <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	return lines
+})
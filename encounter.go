@@ -0,0 +1,301 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand"
+	"slices"
+	"strings"
+)
+
+// Trainer record holding where the trainer is
+const locationRecord = "location"
+
+type trainerLocation struct {
+	Area string `json:"area"`
+}
+
+var errNowhere = errors.New("You're not in any area yet, use 'explore <area>' or 'goto <area>' first")
+
+// Location area data, through the cache like everything else.
+func fetchArea(cfg *config, name string) (*Area, error) {
+	data, err := fetch(cfg.cache, cfg.baseURL+"/location-area/"+name+"/")
+	if err != nil {
+		return nil, err
+	}
+	var area *Area
+	if err = json.Unmarshal(data, &area); err != nil {
+		return nil, fmt.Errorf("Unmarshal Error: %w", err)
+	}
+	return area, nil
+}
+
+// Moves the trainer to an area, remembered for the next session too.
+func (cfg *config) moveTo(area string) error {
+	if area == cfg.currentArea {
+		return nil
+	}
+	cfg.currentArea = area
+	return cfg.storage.SetRecord(locationRecord, trainerLocation{Area: area})
+}
+
+// One row of an area's encounter table: how a pokemon shows up there.
+type encounterSlot struct {
+	Pokemon  string
+	Method   string
+	Version  string
+	MinLevel int
+	MaxLevel int
+	Chance   int
+}
+
+// Flattens an area's encounter table, only one game version's part of it when given.
+func encounterSlots(area *Area, version string) []encounterSlot {
+	var slots []encounterSlot
+	for _, encounter := range area.PokemonEncounters {
+		for _, details := range encounter.VersionDetails {
+			if version != "" && details.Version.Name != version {
+				continue
+			}
+			for _, detail := range details.EncounterDetails {
+				slots = append(slots, encounterSlot{
+					Pokemon:  encounter.Pokemon.Name,
+					Method:   detail.Method.Name,
+					Version:  details.Version.Name,
+					MinLevel: detail.MinLevel,
+					MaxLevel: detail.MaxLevel,
+					Chance:   detail.Chance,
+				})
+			}
+		}
+	}
+	return slots
+}
+
+// Encounter methods of an area, such as walk, surf or old-rod, in the order PokeAPI lists them.
+func areaMethods(area *Area, version string) []string {
+	var methods []string
+	for _, rate := range area.EncounterMethodRates {
+		inVersion := version == ""
+		for _, details := range rate.VersionDetails {
+			inVersion = inVersion || details.Version.Name == version
+		}
+		if inVersion && !slices.Contains(methods, rate.EncounterMethod.Name) {
+			methods = append(methods, rate.EncounterMethod.Name)
+		}
+	}
+	for _, slot := range encounterSlots(area, version) {
+		if !slices.Contains(methods, slot.Method) {
+			methods = append(methods, slot.Method)
+		}
+	}
+	return methods
+}
+
+// Chance in percent that trying a method turns anything up, in one version or the best of them.
+// Methods PokeAPI has no rate for always do.
+func methodRate(area *Area, version, method string) int {
+	rate, known := 0, false
+	for _, r := range area.EncounterMethodRates {
+		if r.EncounterMethod.Name != method {
+			continue
+		}
+		for _, details := range r.VersionDetails {
+			if version == "" || details.Version.Name == version {
+				rate, known = max(rate, details.Rate), true
+			}
+		}
+	}
+	if !known {
+		return 100
+	}
+	return rate
+}
+
+// Methods of finding pokemon on foot, what catch uses without --method.
+// Water and rods have fish and surf, the rest are special encounters.
+var landMethods = []string{
+	"walk", "dark-grass", "grass-spots", "cave-spots", "bridge-spots",
+	"rough-terrain", "yellow-flowers", "purple-flowers", "red-flowers",
+}
+
+// Slots of the session's game version and one encounter method, all methods without one.
+func (cfg *config) methodSlots(area *Area, method string) ([]encounterSlot, error) {
+	slots := encounterSlots(area, cfg.gameVersion)
+	if len(slots) == 0 && cfg.gameVersion != "" {
+		return nil, fmt.Errorf("There are no wild pokemon in %s in %s, only in %s. See 'version'", area.Name, cfg.gameVersion, strings.Join(areaVersions(area), ", "))
+	}
+	if method == "" {
+		return slots, nil
+	}
+	slots = slices.DeleteFunc(slots, func(s encounterSlot) bool { return s.Method != method })
+	if len(slots) == 0 {
+		return nil, fmt.Errorf("Nothing turns up by %s in %s, try %s", method, area.Name, strings.Join(areaMethods(area, cfg.gameVersion), ", "))
+	}
+	return slots, nil
+}
+
+// Species in the slots, in the order they first appear.
+func slotPokemon(slots []encounterSlot) []string {
+	var names []string
+	for _, slot := range slots {
+		if !slices.Contains(names, slot.Pokemon) {
+			names = append(names, slot.Pokemon)
+		}
+	}
+	return names
+}
+
+// Picks the slot a wild pokemon comes from. Every game version lists its own table
+// for each method, with chances adding up to 100 on their own, so a version and a
+// method are picked first, then a slot of that table by chance.
+func pickEncounter(rng *rand.Rand, slots []encounterSlot) (encounterSlot, bool) {
+	for _, field := range []func(encounterSlot) string{
+		func(s encounterSlot) string { return s.Version },
+		func(s encounterSlot) string { return s.Method },
+	} {
+		var values []string
+		for _, slot := range slots {
+			if !slices.Contains(values, field(slot)) {
+				values = append(values, field(slot))
+			}
+		}
+		if len(values) == 0 {
+			return encounterSlot{}, false
+		}
+		picked := values[rng.Intn(len(values))]
+		slots = slices.DeleteFunc(slices.Clone(slots), func(s encounterSlot) bool { return field(s) != picked })
+	}
+
+	total := 0
+	for _, slot := range slots {
+		total += max(slot.Chance, 0)
+	}
+	if total == 0 {
+		return encounterSlot{}, false
+	}
+	roll := rng.Intn(total)
+	for _, slot := range slots {
+		if roll < max(slot.Chance, 0) {
+			return slot, true
+		}
+		roll -= max(slot.Chance, 0)
+	}
+	return slots[len(slots)-1], true
+}
+
+// Level of the wild pokemon, anywhere in the slot's range.
+func (s encounterSlot) rollLevel(rng *rand.Rand) int {
+	low := max(s.MinLevel, 1)
+	high := max(s.MaxLevel, low)
+	return low + rng.Intn(high-low+1)
+}
+
+// Chance of a wild pokemon being shiny when the profile doesn't say otherwise, 1 in this
+const defaultShinyOdds = 4096
+
+// Rolls whether a wild pokemon is shiny, at the profile's odds.
+func (cfg *config) rollShiny() (bool, error) {
+	settings, err := cfg.settings()
+	if err != nil {
+		return false, err
+	}
+	return cfg.rng.Intn(cmp.Or(settings.ShinyOdds, defaultShinyOdds)) == 0, nil
+}
+
+// Travels to an area without listing its pokemon, or shows the current one
+func commandGoto(cfg *config, args []string, flags cmdFlags) (any, error) {
+	if len(args) < 1 {
+		if cfg.currentArea == "" {
+			return nil, errNowhere
+		}
+		return locationResult{Area: cfg.currentArea}, nil
+	}
+
+	area, err := fetchArea(cfg, args[0])
+	if errors.Is(err, errNotFound) {
+		return suggestArea(cfg, args[0], "goto", flags, commandGoto)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Get Error: %w", err)
+	}
+	if err = cfg.moveTo(area.Name); err != nil {
+		return nil, err
+	}
+	return locationResult{Area: area.Name}, nil
+}
+
+// Fishing rods, each with its own encounter table
+var rods = []string{"old-rod", "good-rod", "super-rod"}
+
+// Rod each fishing method needs
+var methodRods = map[string]string{
+	"old-rod":         "old-rod",
+	"good-rod":        "good-rod",
+	"super-rod":       "super-rod",
+	"super-rod-spots": "super-rod",
+}
+
+// Checks the bag has the rod a method needs, other methods need none.
+func (cfg *config) checkRod(method string) error {
+	rod, fishing := methodRods[method]
+	if !fishing {
+		return nil
+	}
+	b, err := cfg.bag()
+	if err != nil {
+		return err
+	}
+	if b[rod] < 1 {
+		return fmt.Errorf("You have no %s! Get one with 'bag add %s'", toDisplay(rod), rod)
+	}
+	return nil
+}
+
+// Casts a rod in the current area and throws at whatever bites
+func commandFish(cfg *config, args []string, flags cmdFlags) (any, error) {
+	if cfg.currentArea == "" {
+		return nil, errNowhere
+	}
+	rod := toSlug(cmp.Or(flags.get("rod"), "old"))
+	if !strings.HasSuffix(rod, "-rod") {
+		rod += "-rod"
+	}
+	if !slices.Contains(rods, rod) {
+		return nil, fmt.Errorf("There is no %s, fish with an old, good or super rod", toDisplay(rod))
+	}
+	if err := cfg.checkRod(rod); err != nil {
+		return nil, err
+	}
+
+	area, err := fetchArea(cfg, cfg.currentArea)
+	if err != nil {
+		return nil, fmt.Errorf("Get Error: %w", err)
+	}
+	if _, err = cfg.methodSlots(area, rod); err != nil {
+		return nil, err
+	}
+	//Not every cast gets a bite
+	if cfg.rng.Intn(100) >= methodRate(area, cfg.gameVersion, rod) {
+		return noEncounterResult{Area: area.Name, Method: rod}, nil
+	}
+	return commandCatch(cfg, args, withMethod(flags, rod))
+}
+
+// Surfs the current area and throws at whatever shows up
+func commandSurf(cfg *config, args []string, flags cmdFlags) (any, error) {
+	return commandCatch(cfg, args, withMethod(flags, "surf"))
+}
+
+// Catch flags with the encounter method set.
+func withMethod(flags cmdFlags, method string) cmdFlags {
+	flags = maps.Clone(flags)
+	if flags == nil {
+		flags = cmdFlags{}
+	}
+	flags["method"] = method
+	return flags
+}
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Item that stands in for trading, as the Linking Cord does in the games
+const linkingCord = "linking-cord"
+
+// Evolution chain struct from JSON, a species tree from the first stage on, taken from PokeAPI
+type EvolutionChain struct {
+	ID    int           `json:"id"`
+	Chain evolutionLink `json:"chain"`
+}
+
+// One species of a chain and what it evolves into.
+type evolutionLink struct {
+	Species struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"species"`
+	EvolutionDetails []evolutionDetail `json:"evolution_details"`
+	EvolvesTo        []evolutionLink   `json:"evolves_to"`
+}
+
+// Named PokeAPI resource in an evolution detail
+type evolutionResource struct {
+	Name string `json:"name"`
+}
+
+// How a species evolves from the one before it. Conditions that are nil, false or
+// empty are not needed, the rest all have to be met.
+type evolutionDetail struct {
+	Trigger      evolutionResource  `json:"trigger"`
+	MinLevel     *int               `json:"min_level"`
+	MinHappiness *int               `json:"min_happiness"`
+	Item         *evolutionResource `json:"item"`
+	HeldItem     *evolutionResource `json:"held_item"`
+	KnownMove    *evolutionResource `json:"known_move"`
+	TimeOfDay    string             `json:"time_of_day"`
+
+	// Conditions the Pokedex can't check, an evolution that needs any of them never happens here.
+	Location              *evolutionResource `json:"location"`
+	Gender                *int               `json:"gender"`
+	KnownMoveType         *evolutionResource `json:"known_move_type"`
+	PartySpecies          *evolutionResource `json:"party_species"`
+	PartyType             *evolutionResource `json:"party_type"`
+	RelativePhysicalStats *int               `json:"relative_physical_stats"`
+	NeedsOverworldRain    bool               `json:"needs_overworld_rain"`
+	TurnUpsideDown        bool               `json:"turn_upside_down"`
+	MinAffection          *int               `json:"min_affection"`
+	MinBeauty             *int               `json:"min_beauty"`
+	TradeSpecies          *evolutionResource `json:"trade_species"`
+}
+
+// The conditions of an evolution the Pokedex can't check, described.
+func (d evolutionDetail) unchecked() []string {
+	var needs []string
+	if d.MinHappiness != nil {
+		needs = append(needs, "high friendship")
+	}
+	if d.MinAffection != nil {
+		needs = append(needs, "high affection")
+	}
+	if d.MinBeauty != nil {
+		needs = append(needs, "high beauty")
+	}
+	if d.Location != nil {
+		needs = append(needs, "being at "+toDisplay(d.Location.Name))
+	}
+	if d.Gender != nil {
+		needs = append(needs, cmp.Or(map[int]string{1: "being female", 2: "being male"}[*d.Gender], "a certain gender"))
+	}
+	if d.KnownMoveType != nil {
+		needs = append(needs, "knowing a "+d.KnownMoveType.Name+" move")
+	}
+	if d.PartySpecies != nil {
+		needs = append(needs, "a "+d.PartySpecies.Name+" in the party")
+	}
+	if d.PartyType != nil {
+		needs = append(needs, "a "+d.PartyType.Name+" pokemon in the party")
+	}
+	if d.RelativePhysicalStats != nil {
+		needs = append(needs, map[int]string{1: "more attack than defense", 0: "equal attack and defense", -1: "more defense than attack"}[*d.RelativePhysicalStats])
+	}
+	if d.NeedsOverworldRain {
+		needs = append(needs, "rain")
+	}
+	if d.TurnUpsideDown {
+		needs = append(needs, "holding the game upside down")
+	}
+	if d.TradeSpecies != nil {
+		needs = append(needs, "a trade for a "+d.TradeSpecies.Name)
+	}
+	return needs
+}
+
+// Evolution chain of a species. The chain's URL is only used for its ID, so -base-url applies to it too.
+func fetchEvolutionChain(cfg *config, species *Species) (*EvolutionChain, error) {
+	id := path.Base(strings.TrimSuffix(species.EvolutionChain.URL, "/"))
+	data, err := fetch(cfg.cache, cfg.baseURL+"/evolution-chain/"+id)
+	if err != nil {
+		return nil, fmt.Errorf("Error Fetching Evolution Chain: %w", err)
+	}
+	var chain *EvolutionChain
+	if err = json.Unmarshal(data, &chain); err != nil {
+		return nil, fmt.Errorf("Error Reading Json: %w", err)
+	}
+	return chain, nil
+}
+
+// The link of a species, nil if it isn't in this part of the chain.
+func (l *evolutionLink) find(species string) *evolutionLink {
+	if l.Species.Name == species {
+		return l
+	}
+	for i := range l.EvolvesTo {
+		if found := l.EvolvesTo[i].find(species); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// One way a pokemon can evolve.
+type evolutionOption struct {
+	Into   string
+	Detail evolutionDetail
+}
+
+// Every way a caught pokemon can evolve, empty if it is the last of its chain.
+func (cfg *config) evolutionsOf(c *Caught) ([]evolutionOption, error) {
+	species, err := fetchSpecies(cfg, c.Pokemon)
+	if err != nil {
+		return nil, err
+	}
+	if species.EvolutionChain.URL == "" {
+		return nil, nil
+	}
+	chain, err := fetchEvolutionChain(cfg, species)
+	if err != nil {
+		return nil, err
+	}
+	link := chain.Chain.find(species.Name)
+	if link == nil {
+		return nil, nil
+	}
+	var options []evolutionOption
+	for _, next := range link.EvolvesTo {
+		for _, detail := range next.EvolutionDetails {
+			options = append(options, evolutionOption{Into: next.Species.Name, Detail: detail})
+		}
+	}
+	return options, nil
+}
+
+// What an evolution takes, as in "level 16" or "a Thunder Stone".
+func (d evolutionDetail) String() string {
+	var needs []string
+	switch d.Trigger.Name {
+	case "use-item":
+		if d.Item != nil {
+			needs = append(needs, "a "+toDisplay(d.Item.Name))
+		}
+	case "trade":
+		needs = append(needs, "a trade (or a "+toDisplay(linkingCord)+")")
+	case "level-up":
+		if d.MinLevel != nil {
+			needs = append(needs, fmt.Sprintf("level %d", *d.MinLevel))
+		} else {
+			needs = append(needs, "a level up")
+		}
+	default:
+		needs = append(needs, strings.ReplaceAll(d.Trigger.Name, "-", " "))
+	}
+	needs = append(needs, d.unchecked()...)
+	if d.HeldItem != nil {
+		needs = append(needs, "holding a "+toDisplay(d.HeldItem.Name))
+	}
+	if d.KnownMove != nil {
+		needs = append(needs, "knowing "+toDisplay(d.KnownMove.Name))
+	}
+	if d.TimeOfDay != "" {
+		needs = append(needs, "at "+d.TimeOfDay)
+	}
+	return strings.Join(needs, ", ")
+}
+
+// Whether the pokemon meets every condition, with the items in the bag and the time now.
+// Friendship, locations, party members and the like aren't tracked, so evolutions that need them never happen.
+func (d evolutionDetail) met(c *Caught, b bag, now time.Time) bool {
+	switch d.Trigger.Name {
+	case "level-up":
+		if d.MinLevel != nil && c.Level < *d.MinLevel {
+			return false
+		}
+	case "use-item":
+		if d.Item == nil || b[d.Item.Name] < 1 {
+			return false
+		}
+	case "trade":
+		if b[linkingCord] < 1 {
+			return false
+		}
+	default:
+		return false
+	}
+	if len(d.unchecked()) > 0 {
+		return false
+	}
+	if d.HeldItem != nil && c.Item != d.HeldItem.Name {
+		return false
+	}
+	if d.KnownMove != nil && !slices.Contains(c.Moves, d.KnownMove.Name) {
+		return false
+	}
+	day := now.Hour() >= 6 && now.Hour() < 18
+	switch d.TimeOfDay {
+	case "":
+		return true
+	case "day":
+		return day
+	case "night":
+		return !day
+	}
+	return false
+}
+
+// Turns a catch into the species it evolves into and takes what the evolution used up.
+// Everything else about the catch stays, the caller stores the change.
+func (cfg *config) evolveInto(c *Caught, option evolutionOption) error {
+	species, err := fetchSpeciesNamed(cfg, option.Into)
+	if err != nil {
+		return err
+	}
+	form := species.Name
+	for _, variety := range species.Varieties {
+		if variety.IsDefault {
+			form = variety.Pokemon.Name
+		}
+	}
+	data, err := fetch(cfg.cache, cfg.baseURL+"/pokemon/"+form)
+	if err != nil {
+		return fmt.Errorf("Error Fetching URL: %w", err)
+	}
+	var mon *Pokemon
+	if err = json.Unmarshal(data, &mon); err != nil {
+		return fmt.Errorf("Error Reading Json: %w", err)
+	}
+
+	switch option.Detail.Trigger.Name {
+	case "use-item":
+		err = cfg.takeItem(option.Detail.Item.Name)
+	case "trade":
+		err = cfg.takeItem(linkingCord)
+	}
+	if err != nil {
+		return err
+	}
+	//Held items that trigger an evolution are used up by it
+	if option.Detail.HeldItem != nil {
+		c.Item = ""
+	}
+	//An ability the new form can't have goes back to its default
+	kept := false
+	for _, ability := range mon.Abilities {
+		kept = kept || ability.Ability.Name == c.Ability
+	}
+	if !kept {
+		c.Ability = ""
+	}
+	c.Pokemon = mon
+	return nil
+}
+
+// Asks whether a pokemon that just leveled up may evolve, when a level up is all it needs.
+// Returns the species it evolved into, empty if it didn't.
+func (cfg *config) offerEvolution(c *Caught) (string, error) {
+	options, err := cfg.evolutionsOf(c)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	for _, option := range options {
+		if option.Detail.Trigger.Name != "level-up" || !option.Detail.met(c, nil, now) {
+			continue
+		}
+		if !confirm(fmt.Sprintf("What? %s is evolving into %s! Let it evolve?", c.Name(), option.Into)) {
+			return "", nil
+		}
+		if err = cfg.evolveInto(c, option); err != nil {
+			return "", err
+		}
+		return option.Into, nil
+	}
+	return "", nil
+}
+
+// Evolves a caught pokemon whose conditions are met
+func commandEvolve(cfg *config, args []string, _ cmdFlags) (any, error) {
+	if len(args) < 1 {
+		return nil, errUsage
+	}
+	caught, err := findCaught(cfg.storage, args[0])
+	if err != nil {
+		return nil, err
+	}
+	options, err := cfg.evolutionsOf(caught)
+	if err != nil {
+		return nil, err
+	}
+	if len(options) == 0 {
+		return nil, fmt.Errorf("%s doesn't evolve", caught.Name())
+	}
+
+	//A species to evolve into narrows the options down, for pokemon like eevee
+	if len(args) > 1 {
+		into := toSlug(args[1])
+		var intos []string
+		for _, option := range options {
+			if !slices.Contains(intos, option.Into) {
+				intos = append(intos, option.Into)
+			}
+		}
+		options = slices.DeleteFunc(options, func(o evolutionOption) bool { return o.Into != into })
+		if len(options) == 0 {
+			return nil, fmt.Errorf("%s can't evolve into %s, only into %s", caught.Name(), args[1], strings.Join(intos, " or "))
+		}
+	}
+
+	b, err := cfg.bag()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var ready, needs []string
+	var chosen evolutionOption
+	for _, option := range options {
+		if !option.Detail.met(caught, b, now) {
+			needs = append(needs, fmt.Sprintf("%s needs %s", option.Into, option.Detail))
+			continue
+		}
+		if !slices.Contains(ready, option.Into) {
+			ready = append(ready, option.Into)
+			chosen = option
+		}
+	}
+	switch {
+	case len(ready) == 0:
+		return nil, fmt.Errorf("%s can't evolve yet: %s", caught.Name(), strings.Join(needs, "; "))
+	case len(ready) > 1:
+		return nil, fmt.Errorf("%s can evolve into %s, pick one: evolve %s <species>", caught.Name(), strings.Join(ready, " or "), args[0])
+	}
+
+	result := evolveResult{ID: caught.ID, Name: caught.Name(), From: caught.Pokemon.Name, Into: chosen.Into}
+	if err = cfg.evolveInto(caught, chosen); err != nil {
+		return nil, err
+	}
+	if err = cfg.storage.Update(caught); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Highest level a pokemon can reach
+const maxLevel = 100
+
+// Growth rate struct from JSON, the experience each level takes, taken from PokeAPI
+type GrowthRate struct {
+	Name   string `json:"name"`
+	Levels []struct {
+		Level      int `json:"level"`
+		Experience int `json:"experience"`
+	} `json:"levels"`
+}
+
+// Total experience needed to reach level.
+func (g *GrowthRate) experienceAt(level int) int {
+	for _, l := range g.Levels {
+		if l.Level == level {
+			return l.Experience
+		}
+	}
+	return 0
+}
+
+// Level a pokemon with exp experience is at.
+func (g *GrowthRate) levelFor(exp int) int {
+	levels := append(g.Levels[:0:0], g.Levels...)
+	sort.Slice(levels, func(i, j int) bool { return levels[i].Level < levels[j].Level })
+	level := 1
+	for _, l := range levels {
+		if l.Experience <= exp {
+			level = l.Level
+		}
+	}
+	return min(level, maxLevel)
+}
+
+// The curve a pokemon's species levels up along.
+func fetchGrowthRate(cfg *config, mon *Pokemon) (*GrowthRate, error) {
+	species, err := fetchSpecies(cfg, mon)
+	if err != nil {
+		return nil, err
+	}
+	data, err := fetch(cfg.cache, cfg.baseURL+"/growth-rate/"+species.GrowthRate.Name)
+	if err != nil {
+		return nil, fmt.Errorf("Error Fetching Growth Rate: %w", err)
+	}
+	var rate *GrowthRate
+	if err = json.Unmarshal(data, &rate); err != nil {
+		return nil, fmt.Errorf("Error Reading Json: %w", err)
+	}
+	return rate, nil
+}
+
+// Experience for beating or catching a wild pokemon, the Gen I to IV formula
+// without the bonuses for trainer battles and traded pokemon.
+func experienceYield(wild *Pokemon, level int) int {
+	return max(1, wild.BaseExperience*level/7)
+}
+
+// Adds experience to a catch and stores it, returning each level it grew.
+// A pokemon that grew enough to evolve is asked about it.
+// Catches from before experience was tracked start at the bottom of their level.
+func (cfg *config) gainExperience(c *Caught, exp int) ([]levelUp, error) {
+	rate, err := fetchGrowthRate(cfg, c.Pokemon)
+	if err != nil {
+		return nil, err
+	}
+	c.Level = max(c.Level, 1)
+	c.Experience = max(c.Experience, rate.experienceAt(c.Level))
+	c.Experience = min(c.Experience+exp, rate.experienceAt(maxLevel))
+
+	var ups []levelUp
+	before, _ := computeStats(c)
+	for level := rate.levelFor(c.Experience); c.Level < level; {
+		c.Level++
+		after, _ := computeStats(c)
+		ups = append(ups, newLevelUp(c, before, after))
+		before = after
+	}
+	if len(ups) > 0 {
+		evolved, err := cfg.offerEvolution(c)
+		if err != nil {
+			return ups, err
+		}
+		ups[len(ups)-1].Evolved = evolved
+	}
+	return ups, cfg.storage.Update(c)
+}
+
+// A level gained, with how much each stat went up. Catches without IVs only get the level.
+func newLevelUp(c *Caught, before, after statSpread) levelUp {
+	return levelUp{
+		ID:    c.ID,
+		Name:  c.Name(),
+		Level: c.Level,
+		Gains: statSpread{
+			HP:             after.HP - before.HP,
+			Attack:         after.Attack - before.Attack,
+			Defense:        after.Defense - before.Defense,
+			SpecialAttack:  after.SpecialAttack - before.SpecialAttack,
+			SpecialDefense: after.SpecialDefense - before.SpecialDefense,
+			Speed:          after.Speed - before.Speed,
+		},
+	}
+}
+
+// Gives experience from a wild pokemon to the whole party, like the Exp. Share does.
+// Returns how much each member got, nothing when no one could take it.
+func (cfg *config) shareExperience(wild *Pokemon, level int, except int) (int, []levelUp, error) {
+	exp := experienceYield(wild, level)
+	shared := false
+	var ups []levelUp
+	for _, member := range boxContents(cfg.storage, partyBox) {
+		if member.ID == except || member.Level >= maxLevel {
+			continue
+		}
+		memberUps, err := cfg.gainExperience(member, exp)
+		if err != nil {
+			return 0, ups, err
+		}
+		shared = true
+		ups = append(ups, memberUps...)
+	}
+	if !shared {
+		return 0, nil, nil
+	}
+	return exp, ups, nil
+}
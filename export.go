@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Report formats export can write, each turns the whole collection into a document.
+var exporters = map[string]func(w io.Writer, box []*Caught) error{
+	"csv":      tabular(exportCSV),
+	"markdown": tabular(exportMarkdown),
+	"html":     tabular(exportHTML),
+	"showdown": exportShowdown,
+}
+
+// Table formats share one row per pokemon
+func tabular(export func(w io.Writer, rows []exportRow) error) func(io.Writer, []*Caught) error {
+	return func(w io.Writer, box []*Caught) error {
+		rows := make([]exportRow, 0, len(box))
+		for _, caught := range box {
+			rows = append(rows, newExportRow(caught))
+		}
+		return export(w, rows)
+	}
+}
+
+// Base stats in the order the games list them
+var statOrder = []string{"hp", "attack", "defense", "special-attack", "special-defense", "speed"}
+
+// One caught pokemon flattened for reports.
+type exportRow struct {
+	ID       int
+	DexID    int
+	Species  string
+	Nickname string
+	Types    []string
+	Stats    []int
+	CaughtAt time.Time
+	Area     string
+	Ball     string
+	Shiny    bool
+	Location string
+}
+
+func newExportRow(c *Caught) exportRow {
+	row := exportRow{
+		ID:       c.ID,
+		DexID:    c.Pokemon.ID,
+		Species:  c.Pokemon.Name,
+		Nickname: c.Nickname,
+		CaughtAt: c.CaughtAt,
+		Area:     c.Area,
+		Ball:     c.Ball,
+		Shiny:    c.Shiny,
+		Location: describeSlot(c.Box, c.Slot),
+		Stats:    make([]int, len(statOrder)),
+	}
+	for _, t := range c.Pokemon.Types {
+		row.Types = append(row.Types, t.Type.Name)
+	}
+	for _, stat := range c.Pokemon.Stats {
+		if i := slices.Index(statOrder, stat.Stat.Name); i >= 0 {
+			row.Stats[i] = stat.BaseStat
+		}
+	}
+	return row
+}
+
+// Column titles shared by every table format
+func exportHeader() []string {
+	header := []string{"id", "dex_id", "species", "nickname", "types"}
+	header = append(header, statOrder...)
+	return append(header, "caught_at", "area", "ball", "shiny", "location")
+}
+
+func (r exportRow) cells() []string {
+	cells := []string{strconv.Itoa(r.ID), strconv.Itoa(r.DexID), r.Species, r.Nickname, strings.Join(r.Types, "/")}
+	for _, stat := range r.Stats {
+		cells = append(cells, strconv.Itoa(stat))
+	}
+	return append(cells, formatTime(r.CaughtAt), r.Area, r.Ball, strconv.FormatBool(r.Shiny), r.Location)
+}
+
+func exportCSV(w io.Writer, rows []exportRow) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportHeader()); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := cw.Write(row.cells()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func exportMarkdown(w io.Writer, rows []exportRow) error {
+	var b bytes.Buffer
+	header := exportHeader()
+	b.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat(" --- |", len(header)) + "\n")
+	for _, row := range rows {
+		cells := row.cells()
+		for i, cell := range cells {
+			//Pipes would end the cell early
+			cells[i] = strings.ReplaceAll(cell, "|", `\|`)
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+var exportPage = template.Must(template.New("export").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Pokedex export</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+th { background: #eee; }
+</style>
+</head>
+<body>
+<h1>Pokedex export</h1>
+<p>{{len .Rows}} pokemon, exported {{.Exported}}</p>
+<table>
+<thead><tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+</body>
+</html>
+`))
+
+func exportHTML(w io.Writer, rows []exportRow) error {
+	cells := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		cells = append(cells, row.cells())
+	}
+	return exportPage.Execute(w, map[string]any{
+		"Header":   exportHeader(),
+		"Rows":     cells,
+		"Exported": time.Now().Format("2006-01-02 15:04"),
+	})
+}
+
+func exportFormats() []string {
+	names := make([]string, 0, len(exporters))
+	for name := range exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Writes the caught collection as a report, to a file or stdout
+func commandExport(cfg *config, args []string, flags cmdFlags) (any, error) {
+	if len(args) < 1 {
+		return nil, errUsage
+	}
+	format := strings.ToLower(args[0])
+	if format == "md" {
+		format = "markdown"
+	}
+	export, known := exporters[format]
+	if !known {
+		return nil, fmt.Errorf("Can't export to %q, use one of: %s", args[0], strings.Join(exportFormats(), ", "))
+	}
+
+	box := cfg.storage.List()
+	if flags.has("party") {
+		box = boxContents(cfg.storage, partyBox)
+	}
+
+	//No file, or -, means stdout, the report itself is the output then
+	if len(args) < 2 || args[1] == "-" {
+		return nil, export(os.Stdout, box)
+	}
+
+	var b bytes.Buffer
+	if err := export(&b, box); err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(args[1], b.Bytes()); err != nil {
+		return nil, err
+	}
+	return exportResult{Format: format, File: args[1], Pokemon: len(box)}, nil
+}
@@ -0,0 +1,125 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Format is an output format chosen with --output.
+type Format string
+
+const (
+	Text Format = "text"
+	JSON Format = "json"
+	YAML Format = "yaml"
+	CSV  Format = "csv"
+)
+
+// Formats lists every supported format, in the order shown to users.
+var Formats = []Format{Text, JSON, YAML, CSV}
+
+// Texter is implemented by results with a human readable form.
+// Results without one are printed with fmt.
+type Texter interface {
+	Text() string
+}
+
+// Tabler is implemented by results that are naturally rows, such as lists.
+// Other results are written to CSV as field/value pairs.
+type Tabler interface {
+	Table() (header []string, rows [][]string)
+}
+
+// Render writes a command result to w in the given format, nil results write nothing.
+func Render(w io.Writer, format Format, v any) error {
+	if v == nil {
+		return nil
+	}
+
+	switch format {
+	case Text:
+		if t, ok := v.(Texter); ok {
+			_, err := fmt.Fprintln(w, t.Text())
+			return err
+		}
+		_, err := fmt.Fprintln(w, v)
+		return err
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case YAML:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return writeYAML(w, data)
+	case CSV:
+		return writeCSV(w, v)
+	}
+	return fmt.Errorf("unsupported output format %q", format)
+}
+
+func writeCSV(w io.Writer, v any) error {
+	var header []string
+	var rows [][]string
+
+	if t, ok := v.(Tabler); ok {
+		header, rows = t.Table()
+	} else {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		root, err := decodeNode(dec)
+		if err != nil {
+			return err
+		}
+		header = []string{"field", "value"}
+		rows = flatten("", root, nil)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// Turns nested JSON into dotted field paths, e.g. "types.0", with their values.
+func flatten(prefix string, n *node, rows [][]string) [][]string {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch n.kind {
+	case 'o':
+		for _, key := range n.keys {
+			rows = flatten(join(key), n.fields[key], rows)
+		}
+	case 'a':
+		for i, item := range n.items {
+			rows = flatten(join(strconv.Itoa(i)), item, rows)
+		}
+	default:
+		if n.scalar == nil {
+			rows = append(rows, []string{prefix, ""})
+		} else {
+			rows = append(rows, []string{prefix, fmt.Sprint(n.scalar)})
+		}
+	}
+	return rows
+}
@@ -0,0 +1,180 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A decoded JSON value that keeps object keys in their original order,
+// so YAML fields come out in struct order like the JSON does.
+type node struct {
+	scalar any // string, json.Number, bool or nil for leaves
+	keys   []string
+	fields map[string]*node
+	items  []*node
+	kind   byte // 's'calar, 'o'bject or 'a'rray
+}
+
+// Writes JSON data as block style YAML.
+func writeYAML(w io.Writer, data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	root, err := decodeNode(dec)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	switch root.kind {
+	case 's':
+		b.WriteString(yamlScalar(root.scalar) + "\n")
+	default:
+		if root.empty() {
+			b.WriteString(root.emptyValue() + "\n")
+		} else {
+			writeNode(&b, root, 0)
+		}
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func decodeNode(dec *json.Decoder) (*node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			n := &node{kind: 'o', fields: map[string]*node{}}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key := keyTok.(string)
+				child, err := decodeNode(dec)
+				if err != nil {
+					return nil, err
+				}
+				n.keys = append(n.keys, key)
+				n.fields[key] = child
+			}
+			_, err = dec.Token()
+			return n, err
+		}
+		n := &node{kind: 'a'}
+		for dec.More() {
+			child, err := decodeNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.items = append(n.items, child)
+		}
+		_, err = dec.Token()
+		return n, err
+	default:
+		return &node{kind: 's', scalar: t}, nil
+	}
+}
+
+func (n *node) empty() bool {
+	return (n.kind == 'o' && len(n.keys) == 0) || (n.kind == 'a' && len(n.items) == 0)
+}
+
+func (n *node) emptyValue() string {
+	if n.kind == 'o' {
+		return "{}"
+	}
+	return "[]"
+}
+
+// Writes a non-empty object or array, each line indented by indent spaces.
+func writeNode(b *strings.Builder, n *node, indent int) {
+	pad := strings.Repeat(" ", indent)
+
+	if n.kind == 'o' {
+		for _, key := range n.keys {
+			child := n.fields[key]
+			b.WriteString(pad + yamlKey(key) + ":")
+			writeValue(b, child, indent+2)
+		}
+		return
+	}
+
+	for _, item := range n.items {
+		b.WriteString(pad + "-")
+		if item.kind == 'o' && !item.empty() {
+			//First field shares the dash line, the rest line up under it
+			var inner strings.Builder
+			writeNode(&inner, item, indent+2)
+			b.WriteString(" " + strings.TrimPrefix(inner.String(), pad+"  "))
+			continue
+		}
+		writeValue(b, item, indent+2)
+	}
+}
+
+// Writes what follows "key:" or "-", either inline or as an indented block.
+func writeValue(b *strings.Builder, n *node, indent int) {
+	switch {
+	case n.kind == 's':
+		b.WriteString(" " + yamlScalar(n.scalar) + "\n")
+	case n.empty():
+		b.WriteString(" " + n.emptyValue() + "\n")
+	default:
+		b.WriteString("\n")
+		writeNode(b, n, indent)
+	}
+}
+
+func yamlKey(key string) string {
+	if needsQuotes(key) {
+		return quote(key)
+	}
+	return key
+}
+
+func yamlScalar(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		if needsQuotes(val) {
+			return quote(val)
+		}
+		return val
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// Plain strings that YAML would read as something else, or that contain
+// indicator characters, get double quotes. JSON strings are valid YAML.
+func needsQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return true
+	}
+	if json.Valid([]byte(s)) {
+		//Numbers, and anything else JSON would parse as non-string
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	return strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.ContainsAny(s, "\n\t")
+}
+
+func quote(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
@@ -1,6 +1,11 @@
 package pokecache
 
 import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 )
@@ -13,12 +18,32 @@ type CacheEntry struct {
 type Cache struct {
 	cachemap map[string]CacheEntry
 	mu       sync.Mutex
+	interval time.Duration
+	done     chan struct{}
+	stop     sync.Once
 }
 
+// On-disk form of an entry, CacheEntry fields are unexported.
+type savedEntry struct {
+	CreatedAt time.Time `json:"created_at"`
+	Val       []byte    `json:"val"`
+}
+
+// NewCache starts a cache whose entries are reaped once older than interval.
+// Call Close to stop the reaper.
 func NewCache(interval time.Duration) *Cache {
-	return &Cache{
+	c := &Cache{
 		cachemap: make(map[string]CacheEntry),
+		interval: interval,
+		done:     make(chan struct{}),
 	}
+	go c.reapLoop(interval)
+	return c
+}
+
+// Close stops the reaper, entries can still be read and saved afterwards.
+func (c *Cache) Close() {
+	c.stop.Do(func() { close(c.done) })
 }
 
 func (c *Cache) Add(key string, val []byte) {
@@ -41,12 +66,73 @@ func (c *Cache) Get(key string) ([]byte, bool) {
 	return entry.val, true
 }
 
+// Save writes every entry to path so a later run can Load them. It writes a
+// temporary file and renames it over path, so a crash never leaves half a cache.
+func (c *Cache) Save(path string) error {
+	c.mu.Lock()
+	saved := make(map[string]savedEntry, len(c.cachemap))
+	for key, entry := range c.cachemap {
+		saved[key] = savedEntry{CreatedAt: entry.createdAt, Val: entry.val}
+	}
+	c.mu.Unlock()
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	//After a successful rename this is a no-op
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load adds the entries saved at path that are still younger than the interval.
+// A missing file is not an error, there is just nothing cached yet.
+func (c *Cache) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var saved map[string]savedEntry
+	if err = json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, entry := range saved {
+		if time.Since(entry.CreatedAt) > c.interval {
+			continue
+		}
+		c.cachemap[key] = CacheEntry{createdAt: entry.CreatedAt, val: entry.Val}
+	}
+	return nil
+}
+
 func (c *Cache) reapLoop(interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
-		<-ticker.C
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		}
 		c.mu.Lock()
 		for key, entry := range c.cachemap {
 			if time.Since(entry.createdAt) > interval {
@@ -0,0 +1,55 @@
+package suggest
+
+import "sort"
+
+// Distance returns the Levenshtein edit distance between a and b.
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// Closest returns up to n candidates close enough to target to be a likely typo, nearest first.
+func Closest(target string, candidates []string, n int) []string {
+	type match struct {
+		name string
+		dist int
+	}
+
+	//Allow roughly one mistake per three characters, but always at least two
+	maxDist := max(2, len([]rune(target))/3)
+
+	var matches []match
+	for _, c := range candidates {
+		if d := Distance(target, c); d <= maxDist {
+			matches = append(matches, match{c, d})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].dist != matches[j].dist {
+			return matches[i].dist < matches[j].dist
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	var out []string
+	for i := 0; i < len(matches) && i < n; i++ {
+		out = append(out, matches[i].name)
+	}
+	return out
+}
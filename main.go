@@ -4,17 +4,18 @@ package main
 //for quick save and recompile
 
 import (
-	"bufio"
+	"cmp"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"io"
-	"math/rand"
-	"net/http"
 	"os"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
-	"github.com/Crimsonchamp/pokedexcli/internal/pokecache"
+	"github.com/Crimsonchamp/pokedexcli/storage"
 )
 
 // Pokemon location struct from JSON, used for listing different areas, taken from PokeAPI
@@ -82,702 +83,777 @@ type Area struct {
 	} `json:"pokemon_encounters"`
 }
 
-// Command struct for functions below.
+// Command struct for functions below, help text is generated from its metadata.
+// Callbacks return a result for the output package to render, or nil for nothing.
 type cliCommand struct {
 	name        string
 	description string
-	callback    func(cache *pokecache.Cache, storage *Storage, arg1 any)
+	usage       string
+	args        []cliArg
+	flags       []cliFlag
+	examples    []string
+	aliases     []string
+	callback    func(cfg *config, args []string, flags cmdFlags) (any, error)
 }
 
-// Struct for the Pokemon themselves, used in Catch command, taken from PokeAPI
-type Pokemon struct {
-	Abilities []struct {
-		Ability struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"ability"`
-		IsHidden bool `json:"is_hidden"`
-		Slot     int  `json:"slot"`
-	} `json:"abilities"`
-	BaseExperience int `json:"base_experience"`
-	Cries          struct {
-		Latest string `json:"latest"`
-		Legacy string `json:"legacy"`
-	} `json:"cries"`
-	Forms []struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"forms"`
-	GameIndices []struct {
-		GameIndex int `json:"game_index"`
-		Version   struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"version"`
-	} `json:"game_indices"`
-	Height                 int    `json:"height"`
-	HeldItems              []any  `json:"held_items"`
-	ID                     int    `json:"id"`
-	IsDefault              bool   `json:"is_default"`
-	LocationAreaEncounters string `json:"location_area_encounters"`
-	Moves                  []struct {
-		Move struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"move"`
-		VersionGroupDetails []struct {
-			LevelLearnedAt  int `json:"level_learned_at"`
-			MoveLearnMethod struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"move_learn_method"`
-			VersionGroup struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version_group"`
-		} `json:"version_group_details"`
-	} `json:"moves"`
-	Name          string `json:"name"`
-	Order         int    `json:"order"`
-	PastAbilities []any  `json:"past_abilities"`
-	PastTypes     []any  `json:"past_types"`
-	Species       struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"species"`
-	Sprites struct {
-		BackDefault      string `json:"back_default"`
-		BackFemale       any    `json:"back_female"`
-		BackShiny        string `json:"back_shiny"`
-		BackShinyFemale  any    `json:"back_shiny_female"`
-		FrontDefault     string `json:"front_default"`
-		FrontFemale      any    `json:"front_female"`
-		FrontShiny       string `json:"front_shiny"`
-		FrontShinyFemale any    `json:"front_shiny_female"`
-		Other            struct {
-			DreamWorld struct {
-				FrontDefault string `json:"front_default"`
-				FrontFemale  any    `json:"front_female"`
-			} `json:"dream_world"`
-			Home struct {
-				FrontDefault     string `json:"front_default"`
-				FrontFemale      any    `json:"front_female"`
-				FrontShiny       string `json:"front_shiny"`
-				FrontShinyFemale any    `json:"front_shiny_female"`
-			} `json:"home"`
-			OfficialArtwork struct {
-				FrontDefault string `json:"front_default"`
-				FrontShiny   string `json:"front_shiny"`
-			} `json:"official-artwork"`
-			Showdown struct {
-				BackDefault      string `json:"back_default"`
-				BackFemale       any    `json:"back_female"`
-				BackShiny        string `json:"back_shiny"`
-				BackShinyFemale  any    `json:"back_shiny_female"`
-				FrontDefault     string `json:"front_default"`
-				FrontFemale      any    `json:"front_female"`
-				FrontShiny       string `json:"front_shiny"`
-				FrontShinyFemale any    `json:"front_shiny_female"`
-			} `json:"showdown"`
-		} `json:"other"`
-		Versions struct {
-			GenerationI struct {
-				RedBlue struct {
-					BackDefault      string `json:"back_default"`
-					BackGray         string `json:"back_gray"`
-					BackTransparent  string `json:"back_transparent"`
-					FrontDefault     string `json:"front_default"`
-					FrontGray        string `json:"front_gray"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"red-blue"`
-				Yellow struct {
-					BackDefault      string `json:"back_default"`
-					BackGray         string `json:"back_gray"`
-					BackTransparent  string `json:"back_transparent"`
-					FrontDefault     string `json:"front_default"`
-					FrontGray        string `json:"front_gray"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"yellow"`
-			} `json:"generation-i"`
-			GenerationIi struct {
-				Crystal struct {
-					BackDefault           string `json:"back_default"`
-					BackShiny             string `json:"back_shiny"`
-					BackShinyTransparent  string `json:"back_shiny_transparent"`
-					BackTransparent       string `json:"back_transparent"`
-					FrontDefault          string `json:"front_default"`
-					FrontShiny            string `json:"front_shiny"`
-					FrontShinyTransparent string `json:"front_shiny_transparent"`
-					FrontTransparent      string `json:"front_transparent"`
-				} `json:"crystal"`
-				Gold struct {
-					BackDefault      string `json:"back_default"`
-					BackShiny        string `json:"back_shiny"`
-					FrontDefault     string `json:"front_default"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"gold"`
-				Silver struct {
-					BackDefault      string `json:"back_default"`
-					BackShiny        string `json:"back_shiny"`
-					FrontDefault     string `json:"front_default"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontTransparent string `json:"front_transparent"`
-				} `json:"silver"`
-			} `json:"generation-ii"`
-			GenerationIii struct {
-				Emerald struct {
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"emerald"`
-				FireredLeafgreen struct {
-					BackDefault  string `json:"back_default"`
-					BackShiny    string `json:"back_shiny"`
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"firered-leafgreen"`
-				RubySapphire struct {
-					BackDefault  string `json:"back_default"`
-					BackShiny    string `json:"back_shiny"`
-					FrontDefault string `json:"front_default"`
-					FrontShiny   string `json:"front_shiny"`
-				} `json:"ruby-sapphire"`
-			} `json:"generation-iii"`
-			GenerationIv struct {
-				DiamondPearl struct {
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"diamond-pearl"`
-				HeartgoldSoulsilver struct {
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"heartgold-soulsilver"`
-				Platinum struct {
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"platinum"`
-			} `json:"generation-iv"`
-			GenerationV struct {
-				BlackWhite struct {
-					Animated struct {
-						BackDefault      string `json:"back_default"`
-						BackFemale       any    `json:"back_female"`
-						BackShiny        string `json:"back_shiny"`
-						BackShinyFemale  any    `json:"back_shiny_female"`
-						FrontDefault     string `json:"front_default"`
-						FrontFemale      any    `json:"front_female"`
-						FrontShiny       string `json:"front_shiny"`
-						FrontShinyFemale any    `json:"front_shiny_female"`
-					} `json:"animated"`
-					BackDefault      string `json:"back_default"`
-					BackFemale       any    `json:"back_female"`
-					BackShiny        string `json:"back_shiny"`
-					BackShinyFemale  any    `json:"back_shiny_female"`
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"black-white"`
-			} `json:"generation-v"`
-			GenerationVi struct {
-				OmegarubyAlphasapphire struct {
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"omegaruby-alphasapphire"`
-				XY struct {
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"x-y"`
-			} `json:"generation-vi"`
-			GenerationVii struct {
-				Icons struct {
-					FrontDefault string `json:"front_default"`
-					FrontFemale  any    `json:"front_female"`
-				} `json:"icons"`
-				UltraSunUltraMoon struct {
-					FrontDefault     string `json:"front_default"`
-					FrontFemale      any    `json:"front_female"`
-					FrontShiny       string `json:"front_shiny"`
-					FrontShinyFemale any    `json:"front_shiny_female"`
-				} `json:"ultra-sun-ultra-moon"`
-			} `json:"generation-vii"`
-			GenerationViii struct {
-				Icons struct {
-					FrontDefault string `json:"front_default"`
-					FrontFemale  any    `json:"front_female"`
-				} `json:"icons"`
-			} `json:"generation-viii"`
-		} `json:"versions"`
-	} `json:"sprites"`
-	Stats []struct {
-		BaseStat int `json:"base_stat"`
-		Effort   int `json:"effort"`
-		Stat     struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"stat"`
-	} `json:"stats"`
-	Types []struct {
-		Slot int `json:"slot"`
-		Type struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"type"`
-	} `json:"types"`
-	Weight int `json:"weight"`
+// Positional argument of a command, used for help text.
+type cliArg struct {
+	name        string
+	description string
 }
 
-// Struct for Pokemon Storage,
-type Storage struct {
-	box map[string]*Pokemon
+// Flag of a command, value is the placeholder shown in help, empty for on/off flags.
+type cliFlag struct {
+	name        string
+	value       string
+	description string
 }
 
-// Help function
-func commandHelp(_ *pokecache.Cache, _ *Storage, _ any) {
-	fmt.Println("\nCommand list:")
-	fmt.Println("-help:Prints this list")
-	fmt.Println("-exit:Exits the Pokedex")
-	fmt.Println("-mapf:Shows next 20 areas")
-	fmt.Println("-mapb:Shows last 20 areas")
-	fmt.Println("-explore area: Replace area with area name from map commands ")
-	fmt.Println("-catch pokemon: Use it's name instead of typing pokemon")
-	fmt.Println("-remove pokemon: Use it's name instead of typing pokemon")
-	fmt.Println("-inspect pokemon: Use it's name instead of typing pokemon")
-	fmt.Println("-pokedex: Lists pokemon you have caught")
-}
+// Returned by callbacks when arguments are missing, the caller prints the command's usage.
+var errUsage = errors.New("incorrect format")
 
-// Exit function
-func commandExit(_ *pokecache.Cache, _ *Storage, _ any) {
-	fmt.Println("Exiting Pokedex!")
-	os.Exit(0)
+// Help function, lists all commands or shows details for one.
+func commandHelp(_ *config, args []string, _ cmdFlags) (any, error) {
+	commands := getCommandMap()
+
+	if len(args) > 0 {
+		cmd, exists := lookupCommand(commands, args[0])
+		if !exists {
+			return nil, fmt.Errorf("No command named %q, type 'help' for commands", args[0])
+		}
+		return newCommandDetail(cmd), nil
+	}
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var list commandList
+	for _, name := range names {
+		list.Commands = append(list.Commands, commandSummary{
+			Name:        name,
+			Description: commands[name].description,
+		})
+	}
+	return list, nil
 }
 
-// Base Url and location pointer for M(ove)F(orward) and M(ove)B(ack)
-var baseURL = "https://pokeapi.co/api/v2/location-area"
-var currentLocation *Location
+// Exit function, ends the session through the shutdown path so nothing is lost
+func commandExit(_ *config, _ []string, _ cmdFlags) (any, error) {
+	return nil, errExit
+}
 
-// Reads and prints location info, then updates page pointers.
-func commandMF(cache *pokecache.Cache, _ *Storage, _ any) {
+// Lists the next page of location areas, then updates page pointers.
+func commandMF(cfg *config, _ []string, _ cmdFlags) (any, error) {
 
 	//Check if first call, regular use or last page.
 	var url string
-	if currentLocation == nil {
-		url = baseURL
-	} else if currentLocation.Next != nil {
-		url = *currentLocation.Next
+	if cfg.currentLocation == nil {
+		url = cfg.baseURL + "/location-area"
+	} else if cfg.currentLocation.Next != nil {
+		url = *cfg.currentLocation.Next
 	} else {
-		fmt.Println("\nLast Page!")
-		return
+		note("\nLast Page!")
+		return nil, nil
 	}
+	return showAreas(cfg, url)
+}
 
-	//initialize data for cache check
-	var data []byte
-	var err error
+// Same as above, but going to previous page.
+func commandMB(cfg *config, _ []string, _ cmdFlags) (any, error) {
+	var url string
 
-	//If data found in cache, use cached data as data, bypass http get, else use http get and add to cache.
-	cacheData, found := cache.Get(url)
-	if found {
-		fmt.Println("\nUsing Cached Data")
-		data = cacheData
+	if cfg.currentLocation == nil {
+		url = cfg.baseURL + "/location-area"
+	} else if cfg.currentLocation.Previous != nil {
+		url = *cfg.currentLocation.Previous
 	} else {
-		fmt.Println("\nFetching New Data")
-		resp, err := http.Get(url)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		defer resp.Body.Close()
-
-		//turn url info into json
-		data, err = io.ReadAll(resp.Body)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
+		note("\nFirst Page!")
+		return nil, nil
+	}
+	return showAreas(cfg, url)
+}
 
-		cache.Add(url, data)
+// Reads a page of location areas for mapf and mapb.
+func showAreas(cfg *config, url string) (any, error) {
+	data, err := fetch(cfg.cache, url)
+	if err != nil {
+		return nil, err
 	}
 
 	var locations Location
 
 	//turn json into Location struct
 	if err = json.Unmarshal(data, &locations); err != nil {
-		fmt.Println(err)
-		return
+		return nil, err
 	}
 
-	//Print each Result of location
-	fmt.Println("\nAreas:")
-	fmt.Println("--------------")
+	page := areaPage{Areas: []string{}}
 	for _, location := range locations.Results {
-		fmt.Println(location.Name)
+		page.Areas = append(page.Areas, location.Name)
 	}
 	//Updates location marker
-	currentLocation = &locations
+	cfg.currentLocation = &locations
+	return page, nil
 }
 
-// Same as above, but going to previous page.
-func commandMB(cache *pokecache.Cache, _ *Storage, _ any) {
-	var url string
+// Reads regional pokemon info for an area.
+func commandExplore(cfg *config, args []string, flags cmdFlags) (any, error) {
+	if len(args) < 1 {
+		return nil, errUsage
+	}
+	query := args[0]
+	note(cfg.baseURL + "/location-area/" + query + "/")
 
-	if currentLocation == nil {
-		url = baseURL
-	} else if currentLocation.Previous != nil {
-		url = *currentLocation.Previous
-	} else {
-		fmt.Println("\nFirst Page!")
-		return
+	//Suggests close area names on a typo
+	area, err := fetchArea(cfg, query)
+	if errors.Is(err, errNotFound) {
+		return suggestArea(cfg, query, "explore", flags, commandExplore)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Get Error: %w", err)
 	}
 
-	//initialize data for cache check
-	var data []byte
-	var err error
+	//Exploring an area also takes you there, catches come from where you are
+	if err = cfg.moveTo(area.Name); err != nil {
+		return nil, err
+	}
 
-	//If data found in cache, use cached data as data, bypass http get, else use http get and add to cache.
-	cacheData, found := cache.Get(url)
-	if found {
-		fmt.Println("\nUsing Cached Data")
-		data = cacheData
-	} else {
-		fmt.Println("\nFetching New Data")
-		resp, err := http.Get(url)
-		if err != nil {
-			fmt.Println(err)
-			return
+	method := toSlug(flags.get("method"))
+	slots, err := cfg.methodSlots(area, method)
+	if err != nil {
+		return nil, err
+	}
+	local := areaPokemon{
+		Area:      area.Name,
+		Version:   cfg.gameVersion,
+		Method:    method,
+		Methods:   areaMethods(area, cfg.gameVersion),
+		Pokemon:   []string{},
+		Exclusive: exclusivePokemon(area),
+	}
+	if method == "" && cfg.gameVersion == "" {
+		//Every pokemon of the area, even those PokeAPI has no encounter details for
+		for _, encounter := range area.PokemonEncounters {
+			local.Pokemon = append(local.Pokemon, encounter.Pokemon.Name)
 		}
-		defer resp.Body.Close()
+	} else {
+		local.Pokemon = append(local.Pokemon, slotPokemon(slots)...)
+	}
+	return local, nil
+}
 
-		//turn url info into json
-		data, err = io.ReadAll(resp.Body)
-		if err != nil {
-			fmt.Println(err)
-			return
+// Attempts to 'catch' pokemon, if successful, adds to storage
+func commandCatch(cfg *config, args []string, flags cmdFlags) (any, error) {
+
+	//Wild pokemon only show up where you are
+	if cfg.currentArea == "" {
+		return nil, errNowhere
+	}
+	area, err := fetchArea(cfg, cfg.currentArea)
+	if err != nil {
+		return nil, fmt.Errorf("Get Error: %w", err)
+	}
+	method := toSlug(flags.get("method"))
+	if err = cfg.checkRod(method); err != nil {
+		return nil, err
+	}
+	slots, err := cfg.methodSlots(area, method)
+	if err != nil {
+		return nil, err
+	}
+	//Without a method it's whatever is found on foot, see fish and surf for the rest
+	if method == "" {
+		slots = slices.DeleteFunc(slots, func(s encounterSlot) bool { return !slices.Contains(landMethods, s.Method) })
+		if len(slots) == 0 {
+			return nil, fmt.Errorf("Nothing turns up on foot in %s, try fish, surf or --method with %s", area.Name, strings.Join(areaMethods(area, cfg.gameVersion), ", "))
 		}
-		cache.Add(url, data)
 	}
 
-	var locations Location
+	//Looking for a particular pokemon narrows the encounter table down to it, suggests local ones on a typo
+	if len(args) > 0 {
+		query := strings.ToLower(args[0])
+		local := slotPokemon(slots)
+		slots = slices.DeleteFunc(slots, func(s encounterSlot) bool { return s.Pokemon != query })
+		if len(slots) == 0 {
+			return suggestPokemon(cfg, query, local, flags)
+		}
+	}
+	wild, found := pickEncounter(cfg.rng, slots)
+	if !found {
+		return nil, fmt.Errorf("No wild pokemon show up in %s", area.Name)
+	}
+	level := wild.rollLevel(cfg.rng)
+	ivs, nature := rollIndividual(cfg.rng)
+	shiny, err := cfg.rollShiny()
+	if err != nil {
+		return nil, err
+	}
 
-	//turn json into Location struct
-	if err = json.Unmarshal(data, &locations); err != nil {
-		fmt.Println(err)
-		return
+	nickname := flags.get("nickname")
+	if err = checkNickname(cfg.storage, 0, nickname); err != nil {
+		return nil, err
+	}
+	ball := ballName(cmp.Or(flags.get("ball"), "poke"))
+	if _, known := ballModifiers[ball]; !known {
+		return nil, fmt.Errorf("There is no %s, throw a poke, great, ultra or master ball", toDisplay(ball))
 	}
 
-	//Print each Result of location
-	fmt.Println("\nAreas:")
-	fmt.Println("--------------")
-	for _, location := range locations.Results {
-		fmt.Println(location.Name)
+	data, err := fetch(cfg.cache, cfg.baseURL+"/pokemon/"+wild.Pokemon)
+	if err != nil {
+		return nil, fmt.Errorf("Error Fetching URL: %w", err)
 	}
-	//Updates location marker
-	currentLocation = &locations
-}
 
-// Reads and prints regional pokemon info, then updates page pointers.
-func commandExplore(cache *pokecache.Cache, _ *Storage, answer any) {
+	//Initiate pokemon variable
+	var mon *Pokemon
 
-	query, ok := answer.(string)
-	if !ok {
-		fmt.Println("Error, Incorrect Format - Use: Explore Area")
-		return
+	//Fill mon with unmarshalled data
+	if err = json.Unmarshal(data, &mon); err != nil {
+		return nil, fmt.Errorf("Error Reading Json: %w", err)
 	}
 
-	//initialize data for cache check
-	var data []byte
-	var err error
+	//How hard it is to catch comes from the species, how worn down it is from the flags
+	species, err := fetchSpecies(cfg, mon)
+	if err != nil {
+		return nil, err
+	}
+	target, err := parseCatchTarget(species, flags)
+	if err != nil {
+		return nil, err
+	}
+	growth, err := fetchGrowthRate(cfg, mon)
+	if err != nil {
+		return nil, err
+	}
 
-	url := "https://pokeapi.co/api/v2/location-area/" + query + "/"
-	fmt.Println(url)
+	//The ball is gone whether it works or not
+	b, err := cfg.bag()
+	if err != nil {
+		return nil, err
+	}
+	if err = cfg.takeItem(ball); err != nil {
+		return nil, err
+	}
 
-	//If data found in cache, use cached data as data, bypass http get, else use http get and add to cache.
-	cacheData, found := cache.Get(url)
-	if found {
-		fmt.Println("\nUsing Cached Data")
-		data = cacheData
-	} else {
-		fmt.Println("\nFetching New Data")
-		resp, err := http.Get(url)
-		if err != nil {
-			fmt.Println("Get Error:", err)
-			return
+	rate := target.rate(ball)
+	checks, caught := throwBall(cfg.rng, rate)
+
+	result := catchResult{Pokemon: mon.Name, Level: level, OnlyIn: exclusivePokemon(area)[wild.Pokemon], Shiny: shiny, Ball: ball, Left: b[ball] - 1, Shakes: min(checks, 3), Chance: catchChance(rate)}
+	switch {
+	case caught:
+		result.Outcome = "caught"
+		result.Caught = true
+		caught := &Caught{
+			Nickname:   nickname,
+			CaughtAt:   time.Now(),
+			Area:       cfg.currentArea,
+			Ball:       ball,
+			Level:      level,
+			Experience: growth.experienceAt(level),
+			Shiny:      shiny,
+			Nature:     nature,
+			IVs:        &ivs,
+			Pokemon:    mon,
 		}
-		defer resp.Body.Close()
+		placeNew(cfg.storage, caught)
+		if err = cfg.storage.Add(caught); err != nil {
+			return nil, fmt.Errorf("%v was caught, but saving failed: %w", mon.Name, err)
+		}
+		result.ID = caught.ID
+		result.Box = caught.Box
 
-		//turn url info into json
-		data, err = io.ReadAll(resp.Body)
+		//The rest of the party learns from the catch
+		result.Experience, result.LevelUps, err = cfg.shareExperience(mon, level, caught.ID)
 		if err != nil {
-			fmt.Println("Data Read Error:", err)
-			return
+			return nil, err
 		}
+	case checks == 3:
+		result.Outcome = "close"
+	default:
+		result.Outcome = "escaped"
+	}
 
-		//Checks if call is empty
-		if resp.StatusCode != http.StatusOK {
-			fmt.Printf("API returned non-OK status: %v\n", resp.Status)
-			fmt.Println("Check for Typo!")
-			return
+	err = cfg.updateStats(func(stats *trainerStats) {
+		stats.Throws++
+		if result.Caught {
+			stats.Caught++
+			if shiny {
+				if stats.Shinies == nil {
+					stats.Shinies = map[string]int{}
+				}
+				stats.Shinies[mon.Name]++
+			}
+		} else {
+			stats.Escaped++
 		}
+	})
+	return result, err
+}
 
-		cache.Add(url, data)
+// Removes pokemon from storage
+func commandRelease(cfg *config, args []string, flags cmdFlags) (any, error) {
+	if len(args) < 1 {
+		return nil, errUsage
 	}
 
-	var area Area
-
-	//turn json into area struct
-	if err = json.Unmarshal(data, &area); err != nil {
-		fmt.Println("Unmarshal Error:", err)
-		return
+	var released []*Caught
+	for _, ref := range args {
+		caught, err := findCaught(cfg.storage, ref)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.ContainsFunc(released, func(c *Caught) bool { return c.ID == caught.ID }) {
+			released = append(released, caught)
+		}
 	}
-
-	//Print each pokemon in area
-	fmt.Println("\nLocal Pokemon:")
-	fmt.Println("--------------")
-	for _, encounter := range area.PokemonEncounters {
-		fmt.Println(encounter.Pokemon.Name)
+	if len(released) > 1 && !flags.has("yes") && !confirm(fmt.Sprintf("Release %d pokemon?", len(released))) {
+		return nil, errors.New("Nothing was released")
 	}
 
-}
-
-// Attempts to 'catch' pokemon, if successful, adds to storage
-func commandCatch(_ *pokecache.Cache, s *Storage, answer any) {
-
-	//Make sure arg is string
-	query, ok := answer.(string)
-	if !ok {
-		fmt.Println("Error, Incorrect Format - Use: catch pokemon")
-		return
+	keep, err := cfg.binDays()
+	if err != nil {
+		return nil, err
 	}
-
-	//Checks if storage already contains said pokemon
-	_, exists := s.box[query]
-	if exists {
-		fmt.Println("Don't be greedy! One per trainer")
-		return
+	result := releaseResult{Days: keep}
+	for _, caught := range released {
+		if err = cfg.storage.Remove(caught.ID); err != nil {
+			return nil, err
+		}
+		if err = cfg.throwAway(caught); err != nil {
+			return nil, err
+		}
+		result.Released = append(result.Released, newPokedexEntry(caught))
 	}
-
-	//Search url+query
-	url := "https://pokeapi.co/api/v2/pokemon/" + query
-
-	resp, err := http.Get(url)
-	if err != nil {
-		fmt.Println("Error Fetching URL: ", err)
+	if err = compactParty(cfg.storage); err != nil {
+		return nil, err
 	}
-
-	//Url > data
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		fmt.Println("Error Reading URL: ", err)
+	if err = cfg.updateStats(func(stats *trainerStats) { stats.Released += len(released) }); err != nil {
+		return nil, err
 	}
+	return result, nil
+}
 
-	//Checks if call is empty
-	if resp.StatusCode != http.StatusOK {
-		fmt.Printf("API returned non-OK status: %v\n", resp.Status)
-		fmt.Println("Check for Typo!")
-		return
+// Shows pokemon stats
+func commandInspect(cfg *config, args []string, _ cmdFlags) (any, error) {
+	if len(args) < 1 {
+		return nil, errUsage
 	}
 
-	//Initiate pokemon variable
-	var mon *Pokemon
-
-	//Fill mon with unmarshalled data
-	if err = json.Unmarshal(data, &mon); err != nil {
-		fmt.Println("Error Reading Json: ", err)
-		return
+	caught, err := findCaught(cfg.storage, args[0])
+	if err != nil {
+		return nil, err
 	}
+	return newPokemonInfo(caught), nil
+}
 
-	// Establish random seed
-
-	randSource := rand.NewSource(time.Now().UnixNano())
-	randGenerator := rand.New(randSource)
-	// Generate a random integer in the range [0, 500)
-	rN := randGenerator.Intn(500)
-
-	fmt.Println("Throwing Pokeball!")
-	if rN >= mon.BaseExperience {
-		fmt.Println(".")
-		fmt.Println(".")
-		fmt.Println(".")
-		fmt.Printf("%v was caught!\n", mon.Name)
-		s.box[mon.Name] = mon
+// Lists pokemon in storage, optionally only those matching an ID, nickname or species
+func commandPokedex(cfg *config, args []string, flags cmdFlags) (any, error) {
+	all := cfg.storage.Query(func(c *Caught) bool {
+		return (len(args) == 0 || matchesCaught(c, args[0])) && (c.Shiny || !flags.has("shiny"))
+	})
 
-	} else if rN < mon.BaseExperience && rN > (mon.BaseExperience/2) {
-		fmt.Println(".")
-		fmt.Println(".")
-		fmt.Printf("%v escaped! So close!\n", mon.Name)
-	} else {
-		fmt.Println(".")
-		fmt.Printf("%v immediately escaped!\n", mon.Name)
+	box := pokedexList{Pokemon: []pokedexEntry{}}
+	for _, caught := range all {
+		box.Pokemon = append(box.Pokemon, newPokedexEntry(caught))
 	}
+	return box, nil
 }
 
-// Removes pokemon from storage
-func commandRelease(_ *pokecache.Cache, s *Storage, answer any) {
-	query, ok := answer.(string)
-	if !ok {
-		fmt.Println("Error, Incorrect Format - Use: remove pokemon")
-		return
-	}
-	_, exists := s.box[query]
-	if !exists {
-		fmt.Println("You have not caught this pokemon!")
-		return
+// Renames a caught pokemon, an empty name clears the nickname
+func commandNickname(cfg *config, args []string, _ cmdFlags) (any, error) {
+	if len(args) < 1 {
+		return nil, errUsage
 	}
-	delete(s.box, query)
-}
 
-// Prints pokemon stats
-func commandInspect(_ *pokecache.Cache, s *Storage, answer any) {
-	query, ok := answer.(string)
-	if !ok {
-		fmt.Println("Error, Incorrect Format - Use: catch pokemon")
-		return
+	caught, err := findCaught(cfg.storage, args[0])
+	if err != nil {
+		return nil, err
 	}
 
-	pokemon, exists := s.box[query]
-	if !exists {
-		fmt.Println("You have not caught this pokemon!")
-		return
+	nickname := ""
+	if len(args) > 1 {
+		nickname = args[1]
 	}
-
-	fmt.Println("Name: ", pokemon.Name)
-	fmt.Println("Height: ", pokemon.Height)
-	fmt.Println("Weight: ", pokemon.Weight)
-	fmt.Println("Stats: ")
-	fmt.Println(" -hp: ", pokemon.Stats[0].BaseStat)
-	fmt.Println(" -attack: ", pokemon.Stats[1].BaseStat)
-	fmt.Println(" -defense: ", pokemon.Stats[2].BaseStat)
-	fmt.Println(" -special attack: ", pokemon.Stats[3].BaseStat)
-	fmt.Println(" -special defense: ", pokemon.Stats[4].BaseStat)
-	fmt.Println(" -speed: ", pokemon.Stats[5].BaseStat)
-	fmt.Println("Types: ")
-	fmt.Println(" - ", pokemon.Types[0].Type.Name)
-	if len(pokemon.Types) > 1 {
-		fmt.Println(" - ", pokemon.Types[1].Type.Name)
+	if err = checkNickname(cfg.storage, caught.ID, nickname); err != nil {
+		return nil, err
 	}
-}
 
-// Prints list of pokemon in storage
-func commandPokedex(_ *pokecache.Cache, s *Storage, _ any) {
-	fmt.Println("Current Box:")
-	for _, pokemon := range s.box {
-		fmt.Println("-", pokemon.ID, " ", pokemon.Name)
+	caught.Nickname = nickname
+	if err = cfg.storage.Update(caught); err != nil {
+		return nil, err
 	}
+	return newPokedexEntry(caught), nil
 }
 
-// Initializes storage for pokemon catching
-func getStorage() *Storage {
-	return &Storage{
-		box: make(map[string]*Pokemon),
-	}
+// Common command errors
+var (
+	errTypo      = errors.New("Check for Typo!")
+	errNotCaught = storage.ErrNotCaught
+)
+
+// Flags of the commands that throw a ball at a wild pokemon
+var throwFlags = []cliFlag{
+	{name: "ball", value: "type", description: "Ball to throw from your bag: poke (the default), great, ultra or master"},
+	{name: "nickname", value: "name", description: "Nickname to give it if caught"},
+	{name: "hp", value: "percent", description: "HP the wild pokemon has left, 100 by default. Lower is easier"},
+	{name: "status", value: "condition", description: "Status of the wild pokemon: sleep or freeze, paralysis, poison or burn"},
 }
 
-// List of commands to pull from, takes cache for map commands.
+// List of commands to pull from, keyed by name.
 func getCommandMap() map[string]cliCommand {
 	return map[string]cliCommand{
 		"help": {
 			name:        "help",
 			description: "Displays a help message",
-			callback:    commandHelp,
+			usage:       "help [command]",
+			args: []cliArg{
+				{name: "command", description: "Optional, shows detailed help for this command"},
+			},
+			examples: []string{"help", "help catch"},
+			aliases:  []string{"?"},
+			callback: commandHelp,
 		},
 		"exit": {
 			name:        "exit",
 			description: "Exit pokedex",
+			usage:       "exit",
+			examples:    []string{"exit"},
+			aliases:     []string{"quit"},
 			callback:    commandExit,
 		},
 		"mapf": {
 			name:        "mapf",
 			description: "Show the next 20 locations",
+			usage:       "mapf",
+			examples:    []string{"mapf"},
+			aliases:     []string{"map"},
 			callback:    commandMF,
 		},
 		"mapb": {
 			name:        "mapb",
 			description: "Shows the previous 20 locations",
+			usage:       "mapb",
+			examples:    []string{"mapb"},
 			callback:    commandMB,
 		},
 		"explore": {
 			name:        "explore",
-			description: "Shows the pokemon in associated area",
-			callback:    commandExplore,
+			description: "Shows the pokemon in associated area and takes you there",
+			usage:       "explore <area> [--method method]",
+			args: []cliArg{
+				{name: "area", description: "Area name as listed by mapf/mapb"},
+			},
+			flags: []cliFlag{
+				{name: "method", value: "method", description: "Only pokemon found this way: walk, surf, old-rod, good-rod, super-rod and so on"},
+			},
+			examples: []string{"explore canalave-city-area", "explore canalave-city-area --method surf"},
+			callback: commandExplore,
+		},
+		"goto": {
+			name:        "goto",
+			description: "Travels to an area, wild pokemon for catch come from there. Shows where you are without an area",
+			usage:       "goto [area]",
+			args: []cliArg{
+				{name: "area", description: "Optional, area name as listed by mapf/mapb"},
+			},
+			examples: []string{"goto", "goto viridian-forest-area"},
+			callback: commandGoto,
+		},
+		"version": {
+			name:        "version",
+			description: "Picks the game version wild pokemon come from for the rest of the session, explore, catch, fish and surf only use its encounters. Shows the current one without a name",
+			usage:       "version [name|all]",
+			args: []cliArg{
+				{name: "name", description: "Optional, a game version such as red or diamond, all to use every version again"},
+			},
+			examples: []string{"version", "version red", "version all"},
+			callback: commandVersion,
 		},
 		"catch": {
-			name:        "explore",
-			description: "Attempts to catch pokemon",
-			callback:    commandCatch,
+			name:        "catch",
+			description: "Attempts to catch a wild pokemon in the current area, the odds follow the games: the species' capture rate, HP left and status",
+			usage:       "catch [pokemon] [--method method] [--ball type] [--nickname name] [--hp percent] [--status condition]",
+			args: []cliArg{
+				{name: "pokemon", description: "Optional, pokemon of the area to look for, whatever shows up if left out"},
+			},
+			flags: append([]cliFlag{
+				{name: "method", value: "method", description: "Only pokemon found this way, such as surf or old-rod, see explore. Those found on foot without it"},
+			}, throwFlags...),
+			examples: []string{"catch", "catch pikachu", "catch pikachu --nickname sparky", "catch mewtwo --ball ultra --hp 1 --status sleep", "catch --method surf"},
+			callback: commandCatch,
+		},
+		"fish": {
+			name:        "fish",
+			description: "Casts a rod from your bag in the current area and throws a ball at whatever bites, better rods find other pokemon",
+			usage:       "fish [pokemon] [--rod old|good|super] [--ball type] [--nickname name] [--hp percent] [--status condition]",
+			args: []cliArg{
+				{name: "pokemon", description: "Optional, pokemon to fish for, whatever bites if left out"},
+			},
+			flags: append([]cliFlag{
+				{name: "rod", value: "old|good|super", description: "Rod to cast, old by default"},
+			}, throwFlags...),
+			examples: []string{"fish", "fish --rod good", "fish gyarados --rod super --ball ultra"},
+			callback: commandFish,
+		},
+		"surf": {
+			name:        "surf",
+			description: "Surfs the current area and throws a ball at whatever shows up on the water",
+			usage:       "surf [pokemon] [--ball type] [--nickname name] [--hp percent] [--status condition]",
+			args: []cliArg{
+				{name: "pokemon", description: "Optional, pokemon to look for on the water, whatever shows up if left out"},
+			},
+			flags:    throwFlags,
+			examples: []string{"surf", "surf tentacool --ball great"},
+			callback: commandSurf,
 		},
 		"release": {
 			name:        "release",
-			description: "Remove Pokemon from storage",
-			callback:    commandRelease,
+			description: "Remove Pokemon from storage, they wait in the bin for a while in case you change your mind",
+			usage:       "release <id|nickname> [id|nickname...]",
+			args: []cliArg{
+				{name: "id|nickname", description: caughtRefHelp + ", give several to release them all"},
+			},
+			flags: []cliFlag{
+				{name: "yes", description: "Don't ask before releasing several pokemon"},
+			},
+			examples: []string{"release 3", "release sparky", "release 3 4 5 --yes"},
+			aliases:  []string{"remove"},
+			callback: commandRelease,
+		},
+		"bag": {
+			name:        "bag",
+			description: "Shows the balls and items in your bag, 'bag add' is a debug command that hands out items for free",
+			usage:       "bag [add <item> [count]]",
+			args: []cliArg{
+				{name: "add <item> [count]", description: "Debug only: puts items in the bag for free, any PokeAPI item name"},
+			},
+			examples: []string{"bag", "bag add great-ball 5", "bag add protein"},
+			callback: commandBag,
+		},
+		"use": {
+			name:        "use",
+			description: "Uses an item from your bag on a caught pokemon, such as vitamins or rare candy",
+			usage:       "use <item> <id|nickname>",
+			args: []cliArg{
+				{name: "item", description: "Item to use, such as protein, hp-up or rare-candy"},
+				{name: "id|nickname", description: caughtRefHelp},
+			},
+			examples: []string{"use protein sparky", "use hp-up 3", "use rare-candy 1"},
+			callback: commandUse,
+		},
+		"evolve": {
+			name:        "evolve",
+			description: "Evolves a caught pokemon that meets its evolution's conditions: level, an item from your bag or a trade, which a linking-cord stands in for",
+			usage:       "evolve <id|nickname> [species]",
+			args: []cliArg{
+				{name: "id|nickname", description: caughtRefHelp},
+				{name: "species", description: "Optional, what to evolve into when it could become several, like eevee"},
+			},
+			examples: []string{"evolve sparky", "evolve 4 flareon"},
+			callback: commandEvolve,
+		},
+		"bin": {
+			name:        "bin",
+			description: "Lists released pokemon, which can be brought back until the profile's bin-days run out",
+			usage:       "bin [restore <id> | empty]",
+			args: []cliArg{
+				{name: "restore <id>", description: "Brings a released pokemon back into the party or a box"},
+				{name: "empty", description: "Lets go of everything in the bin for good"},
+			},
+			flags: []cliFlag{
+				{name: "yes", description: "Don't ask before emptying the bin"},
+			},
+			examples: []string{"bin", "bin restore 3", "bin empty"},
+			callback: commandBin,
+		},
+		"undo": {
+			name:        "undo",
+			description: "Takes back the last command that changed your pokemon, such as catch, release or move",
+			usage:       "undo",
+			examples:    []string{"undo"},
+			callback:    commandUndo,
 		},
 		"inspect": {
 			name:        "inspect",
 			description: "Print Pokemon Stats",
-			callback:    commandInspect,
+			usage:       "inspect <id|nickname>",
+			args: []cliArg{
+				{name: "id|nickname", description: caughtRefHelp},
+			},
+			examples: []string{"inspect 3", "inspect sparky"},
+			callback: commandInspect,
 		},
 		"pokedex": {
 			name:        "pokedex",
 			description: "Prints pokemon in storage",
-			callback:    commandPokedex,
+			usage:       "pokedex [id|nickname|species] [--shiny]",
+			args: []cliArg{
+				{name: "filter", description: "Optional, only list catches with this ID, nickname or species"},
+			},
+			flags: []cliFlag{
+				{name: "shiny", description: "Only list shiny pokemon"},
+			},
+			examples: []string{"pokedex", "pokedex pikachu", "pokedex --shiny"},
+			aliases:  []string{"dex"},
+			callback: commandPokedex,
+		},
+		"party": {
+			name:        "party",
+			description: "Shows the pokemon in your party",
+			usage:       "party",
+			examples:    []string{"party"},
+			callback:    commandParty,
+		},
+		"box": {
+			name:        "box",
+			description: "Shows a PC box",
+			usage:       "box [n]",
+			args: []cliArg{
+				{name: "n", description: "Box number, defaults to 1"},
+			},
+			examples: []string{"box", "box 2"},
+			callback: commandBox,
+		},
+		"deposit": {
+			name:        "deposit",
+			description: "Sends a party pokemon to the PC",
+			usage:       "deposit <id|nickname> [box]",
+			args: []cliArg{
+				{name: "id|nickname", description: caughtRefHelp},
+				{name: "box", description: "Optional, first box to look for a free slot in"},
+			},
+			examples: []string{"deposit 3", "deposit sparky 2"},
+			callback: commandDeposit,
+		},
+		"withdraw": {
+			name:        "withdraw",
+			description: "Takes a pokemon out of the PC into your party",
+			usage:       "withdraw <id|nickname>",
+			args: []cliArg{
+				{name: "id|nickname", description: caughtRefHelp},
+			},
+			examples: []string{"withdraw 12"},
+			callback: commandWithdraw,
+		},
+		"move": {
+			name:        "move",
+			description: "Moves a pokemon to another box or slot, swapping with anything already there",
+			usage:       "move <id|nickname> <box|party> [slot]",
+			args: []cliArg{
+				{name: "id|nickname", description: caughtRefHelp},
+				{name: "box|party", description: "Box number, or party"},
+				{name: "slot", description: "Optional, slot to move to, defaults to the first free one"},
+			},
+			examples: []string{"move 12 2", "move sparky 1 30", "move 12 party 1"},
+			callback: commandMove,
+		},
+		"export": {
+			name:        "export",
+			description: "Writes your caught pokemon to a CSV, Markdown or HTML report, or a Showdown team",
+			usage:       "export <csv|markdown|html|showdown> [file]",
+			args: []cliArg{
+				{name: "format", description: "csv, markdown (or md), html or showdown"},
+				{name: "file", description: "Optional, file to write, prints the report if left out"},
+			},
+			flags: []cliFlag{
+				{name: "party", description: "Only export your party"},
+			},
+			examples: []string{"export csv box.csv", "export markdown", "export html team.html", "export showdown --party team.txt"},
+			callback: commandExport,
+		},
+		"import": {
+			name:        "import",
+			description: "Adds pokemon from a Showdown team paste, checking species and moves against PokeAPI",
+			usage:       "import showdown <file>",
+			args: []cliArg{
+				{name: "format", description: "showdown, the only format that can be imported"},
+				{name: "file", description: "Team paste to read"},
+			},
+			examples: []string{"import showdown team.txt"},
+			callback: commandImport,
+		},
+		"save": {
+			name:        "save",
+			description: "Checks save files, or encrypts the current profile's with a passphrase",
+			usage:       "save verify [file] | save encrypt | save decrypt",
+			args: []cliArg{
+				{name: "verify [file]", description: "Reports whether a save file is valid and untouched, the current profile's if no file is given"},
+				{name: "encrypt", description: "Encrypts the save file with a passphrase, asked for on every start (or set " + passphraseEnv + ")"},
+				{name: "decrypt", description: "Stops encrypting the save file, it keeps a checksum"},
+			},
+			examples: []string{"save verify", "save verify backup.json", "save encrypt"},
+			callback: commandSave,
+		},
+		"profile": {
+			name:        "profile",
+			description: "Shows or manages trainer profiles",
+			usage:       "profile [list | new <name> | switch <name> | set <setting> <value>]",
+			args: []cliArg{
+				{name: "list", description: "Lists every profile"},
+				{name: "new <name>", description: "Creates a profile and switches to it"},
+				{name: "switch <name>", description: "Switches to another profile, it is also used next time"},
+				{name: "set <setting>", description: "Changes a setting of the current profile: output, bin-days for how long released pokemon are kept, or shiny-odds (1 in n, 4096 by default)"},
+			},
+			examples: []string{"profile", "profile new misty", "profile switch default", "profile set output json", "profile set bin-days 7"},
+			callback: commandProfile,
+		},
+		"nickname": {
+			name:        "nickname",
+			description: "Gives a caught pokemon a nickname",
+			usage:       "nickname <id|nickname> [name]",
+			args: []cliArg{
+				{name: "id|nickname", description: caughtRefHelp},
+				{name: "name", description: "New nickname, leave out to clear it"},
+			},
+			examples: []string{"nickname 3 sparky", "nickname sparky"},
+			callback: commandNickname,
 		},
 	}
 }
 
-func main() {
-	fmt.Println("Welcome to a Pokedex!\nType 'help' if you need guidance!")
-
-	cache := pokecache.NewCache(5 * time.Minute)
-
-	storage := getStorage()
+// Finds a command by name or alias.
+func lookupCommand(commands map[string]cliCommand, name string) (cliCommand, bool) {
+	if cmd, exists := commands[name]; exists {
+		return cmd, true
+	}
+	for _, cmd := range commands {
+		for _, alias := range cmd.aliases {
+			if alias == name {
+				return cmd, true
+			}
+		}
+	}
+	return cliCommand{}, false
+}
 
-	commands := getCommandMap()
+func main() {
+	cfg, words, err := parseFlags(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		os.Exit(2)
+	}
 
-	//Scanner set up to read inputs
-	scanner := bufio.NewScanner(os.Stdin)
-	for {
-		fmt.Print("pokedex > ")
+	//Signals are handled from here on, so the save and the cache are always left tidy
+	interrupted()
 
-		// If input is scanned, enter
-		if scanner.Scan() {
-			input := scanner.Text()
-			cmdln := strings.Fields(input)
-			cmdName := ""
-			arg1 := ""
+	if err := cfg.loadState(words); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
-			if len(cmdln) > 0 {
-				cmdName = cmdln[0]
-			}
-			if len(cmdln) > 1 {
-				arg1 = cmdln[1]
-			}
+	//Arguments after the flags run a single command and exit, otherwise start the prompt
+	if len(words) > 0 {
+		os.Exit(runOnce(cfg, words))
+	}
 
-			//Parse Input to command map, trigger input's callback command.
-			if cmd, exists := commands[cmdName]; exists {
-				cmd.callback(cache, storage, arg1)
-			} else {
-				fmt.Println("Sorry I don't understand, type 'help' for commands")
-			}
-		}
+	repl(cfg)
+	if err := cfg.shutdown(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
+	fmt.Println("Exiting Pokedex!")
 }
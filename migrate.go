@@ -0,0 +1,352 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"maps"
+	"math/rand"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Upgrades a save file from one version to the next, both as raw JSON.
+type migration func(data []byte) ([]byte, error)
+
+// Migrations by the version they upgrade from, loading walks the chain up to saveVersion.
+var migrations = map[int]migration{}
+
+// Adds the step from version from to from+1. Whoever bumps saveVersion registers
+// the step to it here, from init like the ones below.
+func registerMigration(from int, migrate migration) {
+	if _, exists := migrations[from]; exists {
+		panic(fmt.Sprintf("save migration from version %d registered twice", from))
+	}
+	migrations[from] = migrate
+}
+
+func init() {
+	//Before versioning the file was just the box, a map of species to pokemon
+	registerMigration(0, func(data []byte) ([]byte, error) {
+		var box map[string]json.RawMessage
+		if err := json.Unmarshal(data, &box); err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]any{"version": 1, "box": box})
+	})
+
+	//Version 1 kept one pokemon per species, each becomes a catch of its own
+	registerMigration(1, func(data []byte) ([]byte, error) {
+		var old struct {
+			Box map[string]*Pokemon `json:"box"`
+		}
+		if err := json.Unmarshal(data, &old); err != nil {
+			return nil, err
+		}
+		//Number them in dex order rather than map order
+		mons := slices.Collect(maps.Values(old.Box))
+		slices.SortFunc(mons, func(a, b *Pokemon) int { return a.ID - b.ID })
+
+		save := saveFile{Version: 2, Box: []*Caught{}}
+		for i, mon := range mons {
+			save.Box = append(save.Box, &Caught{ID: i + 1, Ball: "poke-ball", Pokemon: mon})
+		}
+		return json.Marshal(save)
+	})
+
+	//Version 3 only added sealing around the save, unsealSave has already taken it off
+	registerMigration(2, func(data []byte) ([]byte, error) {
+		var save map[string]json.RawMessage
+		if err := json.Unmarshal(data, &save); err != nil {
+			return nil, err
+		}
+		save["version"] = json.RawMessage("3")
+		return json.Marshal(save)
+	})
+
+	//Version 4 gave every catch a level, IVs and a nature. Older ones get them now,
+	//including those waiting in the bin
+	registerMigration(3, func(data []byte) ([]byte, error) {
+		var save saveFile
+		if err := json.Unmarshal(data, &save); err != nil {
+			return nil, err
+		}
+		for _, c := range save.Box {
+			giveIndividual(c)
+		}
+		if raw, exists := save.Records[binRecord]; exists {
+			var bin []binEntry
+			if err := json.Unmarshal(raw, &bin); err != nil {
+				return nil, err
+			}
+			for _, entry := range bin {
+				giveIndividual(entry.Caught)
+			}
+			raw, err := json.Marshal(bin)
+			if err != nil {
+				return nil, err
+			}
+			save.Records[binRecord] = raw
+		}
+		save.Version = 4
+		return json.Marshal(save)
+	})
+}
+
+// Fills in whatever a catch from before levels is missing. Level 5 is what
+// most of the early game's wild pokemon are. The roll is seeded from the catch's
+// ID and species, so loading the same old save always gives the same pokemon.
+func giveIndividual(c *Caught) {
+	if c == nil {
+		return
+	}
+	seed := fnv.New64a()
+	fmt.Fprintf(seed, "%d", c.ID)
+	if c.Pokemon != nil {
+		fmt.Fprintf(seed, "/%s", c.Pokemon.Name)
+	}
+	ivs, nature := rollIndividual(rand.New(rand.NewSource(int64(seed.Sum64()))))
+	if c.Level < 1 {
+		c.Level = 5
+	}
+	if c.IVs == nil {
+		c.IVs = &ivs
+	}
+	if c.Nature == "" {
+		c.Nature = nature
+	}
+}
+
+// Version a save file says it has, files from before versioning are 0.
+func saveVersionOf(data []byte) (int, error) {
+	var version struct {
+		Version int `json:"version"`
+	}
+	err := json.Unmarshal(data, &version)
+	return version.Version, err
+}
+
+// Runs every migration from version up to saveVersion.
+func migrate(data []byte, version int) ([]byte, error) {
+	for ; version < saveVersion; version++ {
+		step, exists := migrations[version]
+		if !exists {
+			return nil, fmt.Errorf("no migration from save version %d", version)
+		}
+		var err error
+		if data, err = step(data); err != nil {
+			return nil, fmt.Errorf("migrating from save version %d: %w", version, err)
+		}
+	}
+	return data, nil
+}
+
+// Decodes a save file of any known version, returning the version it was written as.
+func decodeSave(data []byte) (*saveFile, int, error) {
+	version, err := saveVersionOf(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if version > saveVersion {
+		return nil, version, fmt.Errorf("it is from a newer version of the Pokedex (save version %d)", version)
+	}
+	if data, err = migrate(data, version); err != nil {
+		return nil, version, err
+	}
+
+	var save saveFile
+	if err = json.Unmarshal(data, &save); err != nil {
+		return nil, version, err
+	}
+	return &save, version, nil
+}
+
+// Copy of a save file as it was before migrating, kept next to it. An older
+// backup of the same version is never replaced, it is the one closest to the original.
+func backupSave(path string, data []byte, version int) error {
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if _, err := os.Stat(backup); err == nil {
+		return nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return writeFileAtomic(backup, data)
+}
+
+// Everything wrong with a decoded save, empty if nothing is.
+func checkSave(save *saveFile) []string {
+	var problems []string
+	ids := map[int]bool{}
+	nicknames := map[string]int{}
+	slots := map[[2]int]int{}
+
+	for i, c := range save.Box {
+		if c == nil {
+			problems = append(problems, fmt.Sprintf("entry %d of the box is empty", i+1))
+			continue
+		}
+		if c.ID < 1 {
+			problems = append(problems, fmt.Sprintf("entry %d has no ID", i+1))
+		} else if ids[c.ID] {
+			problems = append(problems, fmt.Sprintf("ID %d is used twice", c.ID))
+		}
+		ids[c.ID] = true
+		if save.NextID != 0 && c.ID >= save.NextID {
+			problems = append(problems, fmt.Sprintf("#%d is past the next free ID %d", c.ID, save.NextID))
+		}
+
+		if c.Pokemon == nil || c.Pokemon.Name == "" {
+			problems = append(problems, fmt.Sprintf("#%d has no species data", c.ID))
+		}
+		if c.Nickname != "" {
+			key := strings.ToLower(c.Nickname)
+			if other, taken := nicknames[key]; taken {
+				problems = append(problems, fmt.Sprintf("#%d and #%d are both called %s", other, c.ID, c.Nickname))
+			}
+			nicknames[key] = c.ID
+		}
+
+		//No slot yet is fine, loading gives those one
+		if c.Slot == 0 {
+			continue
+		}
+		if c.Box < 0 || c.Slot < 0 || c.Slot > capacity(c.Box) {
+			problems = append(problems, fmt.Sprintf("#%d is in %s, which doesn't exist", c.ID, describeSlot(c.Box, c.Slot)))
+			continue
+		}
+		spot := [2]int{c.Box, c.Slot}
+		if other, taken := slots[spot]; taken {
+			problems = append(problems, fmt.Sprintf("#%d and #%d are both in %s", other, c.ID, describeSlot(c.Box, c.Slot)))
+		}
+		slots[spot] = c.ID
+	}
+
+	for key, record := range save.Records {
+		if !json.Valid(record) {
+			problems = append(problems, fmt.Sprintf("the %s record is not valid JSON", key))
+		}
+	}
+	return problems
+}
+
+// The save file the current profile uses, if its backend keeps one.
+func (cfg *config) savePath() (string, error) {
+	if cfg.storageBackend != "file" {
+		return "", fmt.Errorf("The %s storage backend has no save file", cfg.storageBackend)
+	}
+	if cfg.saveFile != "" {
+		return cfg.saveFile, nil
+	}
+	return profileSaveFile(cfg.profile)
+}
+
+// Checks save files, and turns encryption of the current one on or off
+func commandSave(cfg *config, args []string, _ cmdFlags) (any, error) {
+	if len(args) < 1 {
+		return nil, errUsage
+	}
+
+	switch args[0] {
+	case "verify":
+		path := ""
+		if len(args) > 1 {
+			path = args[1]
+		} else {
+			var err error
+			if path, err = cfg.savePath(); err != nil {
+				return nil, err
+			}
+		}
+		return verifySave(path)
+	case "encrypt", "decrypt":
+		storage, ok := cfg.history.Storage.(*fileStorage)
+		if !ok {
+			return nil, fmt.Errorf("The %s storage backend has no save file", cfg.storageBackend)
+		}
+		if args[0] == "decrypt" {
+			if storage.key == nil {
+				return nil, errors.New("The save file isn't encrypted")
+			}
+			if err := storage.setKey(nil); err != nil {
+				return nil, err
+			}
+			return verifySave(storage.path)
+		}
+
+		passphrase, err := askPassphrase("New passphrase:")
+		if err != nil {
+			return nil, err
+		}
+		if os.Getenv(passphraseEnv) == "" {
+			again, err := askPassphrase("Type it again:")
+			if err != nil {
+				return nil, err
+			}
+			if again != passphrase {
+				return nil, errors.New("The passphrases don't match, the save file was left as it was")
+			}
+		}
+		key, err := newSaveKey(passphrase)
+		if err != nil {
+			return nil, err
+		}
+		if err = storage.setKey(key); err != nil {
+			return nil, err
+		}
+		return saveReport{File: storage.path, Version: saveVersion, Current: saveVersion, Pokemon: len(storage.List()), Integrity: "encrypted", Valid: true, Problems: []string{}}, nil
+	}
+	return nil, errUsage
+}
+
+// Reads a save file like loading it would, and reports what's wrong with it.
+func verifySave(path string) (saveReport, error) {
+	report := saveReport{File: path, Current: saveVersion, Problems: []string{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return report, err
+	}
+
+	version, err := saveVersionOf(data)
+	report.Version = version
+	if err != nil {
+		report.Problems = append(report.Problems, err.Error())
+		return report, nil
+	}
+	save, key, err := unsealSave(path, data)
+	switch {
+	case err != nil:
+		report.Integrity = "failed"
+		report.Problems = append(report.Problems, "the file "+err.Error())
+		return report, nil
+	case version < sealedSince:
+		report.Integrity = "none"
+	case key != nil:
+		report.Integrity = "encrypted"
+	default:
+		report.Integrity = "checksum ok"
+	}
+	//Same rule as loading: a save that was sealed before doesn't get to lose it
+	seal, err := sealMarker(path)
+	if err != nil {
+		return report, err
+	}
+	switch {
+	case seal == sealEncrypted && key == nil:
+		report.Problems = append(report.Problems, "encryption removed, it was encrypted before")
+	case seal != "" && version < sealedSince:
+		report.Problems = append(report.Problems, "seal removed, it had a checksum before")
+	}
+
+	decoded, _, err := decodeSave(save)
+	if err != nil {
+		report.Problems = append(report.Problems, err.Error())
+		return report, nil
+	}
+	report.Pokemon = len(decoded.Box)
+	report.Problems = append(report.Problems, checkSave(decoded)...)
+	report.Valid = len(report.Problems) == 0
+	return report, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeSave(t *testing.T) {
+	tests := []struct {
+		name    string
+		save    string
+		version int
+		species []string
+	}{
+		{
+			name:    "v0 box by species",
+			save:    `{"pikachu": {"id": 25, "name": "pikachu"}, "bulbasaur": {"id": 1, "name": "bulbasaur"}}`,
+			species: []string{"bulbasaur", "pikachu"},
+		},
+		{
+			name:    "v1 versioned box",
+			save:    `{"version": 1, "box": {"pikachu": {"id": 25, "name": "pikachu"}, "bulbasaur": {"id": 1, "name": "bulbasaur"}}}`,
+			version: 1,
+			species: []string{"bulbasaur", "pikachu"},
+		},
+		{
+			name:    "v2 catches",
+			save:    `{"version": 2, "box": [{"id": 1, "ball": "poke-ball", "pokemon": {"id": 25, "name": "pikachu"}}]}`,
+			version: 2,
+			species: []string{"pikachu"},
+		},
+		{
+			name:    "v3 catches",
+			save:    `{"version": 3, "next_id": 3, "box": [{"id": 2, "ball": "great-ball", "level": 12, "nature": "bold", "pokemon": {"id": 1, "name": "bulbasaur"}}]}`,
+			version: 3,
+			species: []string{"bulbasaur"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			save, version, err := decodeSave([]byte(tt.save))
+			if err != nil {
+				t.Fatalf("decodeSave: %v", err)
+			}
+			if version != tt.version {
+				t.Errorf("version = %d, want %d", version, tt.version)
+			}
+			if save.Version != saveVersion {
+				t.Errorf("migrated to version %d, want %d", save.Version, saveVersion)
+			}
+			var species []string
+			for i, c := range save.Box {
+				species = append(species, c.Pokemon.Name)
+				if tt.version < 2 && c.ID != i+1 {
+					t.Errorf("%s has ID %d, want %d", c.Pokemon.Name, c.ID, i+1)
+				}
+				if c.Level < 1 || c.IVs == nil || c.Nature == "" {
+					t.Errorf("%s wasn't given a level, IVs and nature: %+v", c.Pokemon.Name, c)
+				}
+			}
+			if !reflect.DeepEqual(species, tt.species) {
+				t.Errorf("species = %v, want %v", species, tt.species)
+			}
+			if problems := checkSave(save); len(problems) > 0 {
+				t.Errorf("checkSave: %v", problems)
+			}
+		})
+	}
+}
+
+// What a catch is given on the way to version 4 has to be the same every time
+// the old save is loaded, it is only written back later.
+func TestDecodeSaveIsDeterministic(t *testing.T) {
+	old := `{"version": 3, "box": [{"id": 1, "pokemon": {"id": 25, "name": "pikachu"}}],
+		"records": {"bin": [{"caught": {"id": 2, "pokemon": {"id": 1, "name": "bulbasaur"}}}]}}`
+
+	first, _, err := decodeSave([]byte(old))
+	if err != nil {
+		t.Fatalf("decodeSave: %v", err)
+	}
+	for range 3 {
+		again, _, err := decodeSave([]byte(old))
+		if err != nil {
+			t.Fatalf("decodeSave: %v", err)
+		}
+		if !reflect.DeepEqual(first, again) {
+			t.Fatalf("decoding twice differs:\n%+v\n%+v", first.Box[0], again.Box[0])
+		}
+	}
+
+	var bin []binEntry
+	if err = json.Unmarshal(first.Records[binRecord], &bin); err != nil {
+		t.Fatal(err)
+	}
+	if len(bin) != 1 || bin[0].Caught.IVs == nil || bin[0].Caught.Nature == "" {
+		t.Errorf("the catch in the bin wasn't migrated: %s", first.Records[binRecord])
+	}
+}
+
+func TestDecodeSaveFromNewerVersion(t *testing.T) {
+	_, _, err := decodeSave([]byte(`{"version": 99, "box": []}`))
+	if err == nil || !strings.Contains(err.Error(), "newer version") {
+		t.Errorf("err = %v, want one about a newer version", err)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"maps"
+	"math/rand"
+	"slices"
+)
+
+// Stats a nature raises and lowers by 10%, neutral natures have neither.
+type natureEffect struct {
+	up   string
+	down string
+}
+
+// The 25 natures, keyed by PokeAPI name
+var natures = map[string]natureEffect{
+	"hardy":   {},
+	"docile":  {},
+	"serious": {},
+	"bashful": {},
+	"quirky":  {},
+	"lonely":  {up: "attack", down: "defense"},
+	"brave":   {up: "attack", down: "speed"},
+	"adamant": {up: "attack", down: "special-attack"},
+	"naughty": {up: "attack", down: "special-defense"},
+	"bold":    {up: "defense", down: "attack"},
+	"relaxed": {up: "defense", down: "speed"},
+	"impish":  {up: "defense", down: "special-attack"},
+	"lax":     {up: "defense", down: "special-defense"},
+	"timid":   {up: "speed", down: "attack"},
+	"hasty":   {up: "speed", down: "defense"},
+	"jolly":   {up: "speed", down: "special-attack"},
+	"naive":   {up: "speed", down: "special-defense"},
+	"modest":  {up: "special-attack", down: "attack"},
+	"mild":    {up: "special-attack", down: "defense"},
+	"quiet":   {up: "special-attack", down: "speed"},
+	"rash":    {up: "special-attack", down: "special-defense"},
+	"calm":    {up: "special-defense", down: "attack"},
+	"gentle":  {up: "special-defense", down: "defense"},
+	"sassy":   {up: "special-defense", down: "speed"},
+	"careful": {up: "special-defense", down: "special-attack"},
+}
+
+func uniformSpread(v int) statSpread {
+	return statSpread{HP: v, Attack: v, Defense: v, SpecialAttack: v, SpecialDefense: v, Speed: v}
+}
+
+// Nature names in a fixed order, so a seeded session picks the same ones.
+var natureNames = slices.Sorted(maps.Keys(natures))
+
+// Percentage a nature scales a stat by, 100 for stats it leaves alone.
+func (n natureEffect) percent(stat string) int {
+	switch stat {
+	case n.up:
+		return 110
+	case n.down:
+		return 90
+	}
+	return 100
+}
+
+// Random IVs and nature for a wild pokemon.
+func rollIndividual(rng *rand.Rand) (statSpread, string) {
+	var ivs statSpread
+	for _, stat := range statOrder {
+		*ivs.Stat(stat) = rng.Intn(32)
+	}
+	return ivs, natureNames[rng.Intn(len(natureNames))]
+}
+
+// Actual stats at the catch's level, from base stats, IVs, EVs and nature with
+// the formulas of the games since Gen III. False for catches without a level or IVs.
+func computeStats(c *Caught) (statSpread, bool) {
+	var stats statSpread
+	if c.Level < 1 || c.IVs == nil {
+		return stats, false
+	}
+	nature := natures[c.Nature]
+	for _, base := range c.Pokemon.Stats {
+		stat := stats.Stat(base.Stat.Name)
+		if stat == nil {
+			continue
+		}
+		iv, ev := *c.IVs.Stat(base.Stat.Name), *c.EVs.Stat(base.Stat.Name)
+		core := (2*base.BaseStat + iv + ev/4) * c.Level / 100
+		switch {
+		case base.Stat.Name != "hp":
+			*stat = (core + 5) * nature.percent(base.Stat.Name) / 100
+		case base.BaseStat == 1:
+			//Shedinja always has 1 HP
+			*stat = 1
+		default:
+			*stat = core + c.Level + 10
+		}
+	}
+	return stats, true
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The game's storage model on top of Storage: a party plus numbered PC boxes.
+// Box 0 is the party, slots are numbered from 1 in both.
+const (
+	partyBox  = 0
+	partySize = 6
+	boxSize   = 30
+)
+
+var errPartyFull = errors.New("Your party is full! Deposit a pokemon first")
+
+// Where a catch sits, e.g. "party slot 2" or "box 3 slot 14".
+func describeSlot(box, slot int) string {
+	if box == partyBox {
+		return fmt.Sprintf("party slot %d", slot)
+	}
+	return fmt.Sprintf("box %d slot %d", box, slot)
+}
+
+// Catches in a box, or the party, by slot.
+func boxContents(s Storage, box int) []*Caught {
+	contents := s.Query(func(c *Caught) bool { return c.Box == box && c.Slot > 0 })
+	sortBySlot(contents)
+	return contents
+}
+
+func sortBySlot(box []*Caught) {
+	sort.Slice(box, func(i, j int) bool { return box[i].Slot < box[j].Slot })
+}
+
+func capacity(box int) int {
+	if box == partyBox {
+		return partySize
+	}
+	return boxSize
+}
+
+// First empty slot of a box, or 0 if it is full.
+func freeSlot(s Storage, box int) int {
+	used := map[int]bool{}
+	for _, c := range boxContents(s, box) {
+		used[c.Slot] = true
+	}
+	for slot := 1; slot <= capacity(box); slot++ {
+		if !used[slot] {
+			return slot
+		}
+	}
+	return 0
+}
+
+// First empty PC slot at or after box from, new boxes open up as earlier ones fill.
+func freePCSlot(s Storage, from int) (int, int) {
+	for box := max(from, 1); ; box++ {
+		if slot := freeSlot(s, box); slot > 0 {
+			return box, slot
+		}
+	}
+}
+
+// Picks a spot for a new catch: the party first, then the first free box slot.
+func placeNew(s Storage, c *Caught) {
+	if slot := freeSlot(s, partyBox); slot > 0 {
+		c.Box, c.Slot = partyBox, slot
+		return
+	}
+	c.Box, c.Slot = freePCSlot(s, 1)
+}
+
+// Gives a spot to catches that have none, such as ones saved before boxes existed.
+func arrangeStorage(s Storage) error {
+	for _, c := range s.Query(func(c *Caught) bool { return c.Slot == 0 }) {
+		placeNew(s, c)
+		if err := s.Update(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Closes gaps in the party so members stay in slots 1 to n, like in the games.
+func compactParty(s Storage) error {
+	for i, c := range boxContents(s, partyBox) {
+		if c.Slot != i+1 {
+			c.Slot = i + 1
+			if err := s.Update(c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Moves a catch to a box and slot, swapping with whatever is already there.
+func moveCaught(s Storage, c *Caught, box, slot int) error {
+	if slot < 1 || slot > capacity(box) {
+		return fmt.Errorf("Slot must be between 1 and %d", capacity(box))
+	}
+
+	for _, other := range boxContents(s, box) {
+		if other.Slot == slot && other.ID != c.ID {
+			other.Box, other.Slot = c.Box, c.Slot
+			if err := s.Update(other); err != nil {
+				return err
+			}
+		}
+	}
+
+	c.Box, c.Slot = box, slot
+	if err := s.Update(c); err != nil {
+		return err
+	}
+	if err := compactParty(s); err != nil {
+		return err
+	}
+	//Compacting may have moved it up in the party, the caller reports where it ended up
+	if stored, exists := s.Get(c.ID); exists {
+		c.Slot = stored.Slot
+	}
+	return nil
+}
+
+// Shows the party
+func commandParty(cfg *config, _ []string, _ cmdFlags) (any, error) {
+	return newBoxList("Party", partyBox, boxContents(cfg.storage, partyBox)), nil
+}
+
+// Shows a PC box
+func commandBox(cfg *config, args []string, _ cmdFlags) (any, error) {
+	box := 1
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return nil, errUsage
+		}
+		box = n
+	}
+	return newBoxList(fmt.Sprintf("Box %d", box), box, boxContents(cfg.storage, box)), nil
+}
+
+// Sends a party member to the PC
+func commandDeposit(cfg *config, args []string, _ cmdFlags) (any, error) {
+	if len(args) < 1 {
+		return nil, errUsage
+	}
+	caught, err := findCaught(cfg.storage, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if caught.Box != partyBox {
+		return nil, fmt.Errorf("%s is already in box %d", caught.Name(), caught.Box)
+	}
+	if len(boxContents(cfg.storage, partyBox)) == 1 {
+		return nil, errors.New("You can't deposit your last pokemon!")
+	}
+
+	from := 1
+	if len(args) > 1 {
+		if from, err = strconv.Atoi(args[1]); err != nil || from < 1 {
+			return nil, errUsage
+		}
+	}
+	box, slot := freePCSlot(cfg.storage, from)
+	if err = moveCaught(cfg.storage, caught, box, slot); err != nil {
+		return nil, err
+	}
+	return newMoveResult(caught), nil
+}
+
+// Takes a pokemon out of the PC into the party
+func commandWithdraw(cfg *config, args []string, _ cmdFlags) (any, error) {
+	if len(args) < 1 {
+		return nil, errUsage
+	}
+	caught, err := findCaught(cfg.storage, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if caught.Box == partyBox {
+		return nil, fmt.Errorf("%s is already in your party", caught.Name())
+	}
+
+	slot := freeSlot(cfg.storage, partyBox)
+	if slot == 0 {
+		return nil, errPartyFull
+	}
+	if err = moveCaught(cfg.storage, caught, partyBox, slot); err != nil {
+		return nil, err
+	}
+	return newMoveResult(caught), nil
+}
+
+// Moves a pokemon to another box or slot, swapping places with anything already there
+func commandMove(cfg *config, args []string, _ cmdFlags) (any, error) {
+	if len(args) < 2 {
+		return nil, errUsage
+	}
+	caught, err := findCaught(cfg.storage, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	box := partyBox
+	if !strings.EqualFold(args[1], "party") {
+		if box, err = strconv.Atoi(args[1]); err != nil || box < 1 {
+			return nil, errUsage
+		}
+	}
+
+	var slot int
+	if len(args) > 2 {
+		if slot, err = strconv.Atoi(args[2]); err != nil {
+			return nil, errUsage
+		}
+	} else if caught.Box == box {
+		return newMoveResult(caught), nil
+	} else if slot = freeSlot(cfg.storage, box); slot == 0 {
+		if box == partyBox {
+			return nil, errPartyFull
+		}
+		return nil, fmt.Errorf("Box %d is full", box)
+	}
+
+	//Moving the last party member out would leave the party empty
+	if caught.Box == partyBox && box != partyBox && len(boxContents(cfg.storage, partyBox)) == 1 {
+		target := boxContents(cfg.storage, box)
+		if !containsSlot(target, slot) {
+			return nil, errors.New("You can't deposit your last pokemon!")
+		}
+	}
+
+	if err = moveCaught(cfg.storage, caught, box, slot); err != nil {
+		return nil, err
+	}
+	return newMoveResult(caught), nil
+}
+
+func containsSlot(box []*Caught, slot int) bool {
+	for _, c := range box {
+		if c.Slot == slot {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Crimsonchamp/pokedexcli/internal/output"
+	"github.com/Crimsonchamp/pokedexcli/storage"
+)
+
+// Profile used when none was ever picked
+const defaultProfile = "default"
+
+// Profile names end up in file names, so keep them tame
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// Trainer record keys, stored alongside the box through Storage
+const (
+	statsRecord    = "stats"
+	settingsRecord = "settings"
+)
+
+// Running totals for a trainer, shown by profile.
+type trainerStats struct {
+	Started  time.Time `json:"started"`
+	Throws   int       `json:"throws"`
+	Caught   int       `json:"caught"`
+	Escaped  int       `json:"escaped"`
+	Released int       `json:"released"`
+	// Shiny catches by species
+	Shinies map[string]int `json:"shinies,omitempty"`
+}
+
+// Per-trainer settings, changed with profile set.
+type profileSettings struct {
+	Output    output.Format `json:"output,omitempty"`
+	BinDays   int           `json:"bin_days,omitempty"`
+	ShinyOdds int           `json:"shiny_odds,omitempty"`
+}
+
+// Settings profile set knows about, with a check for their values
+var settingKeys = map[string]func(*profileSettings, string) error{
+	"output": func(s *profileSettings, value string) error {
+		if !slices.Contains(output.Formats, output.Format(value)) {
+			return fmt.Errorf("unsupported output format %q, use one of: %s", value, formatNames())
+		}
+		s.Output = output.Format(value)
+		return nil
+	},
+	"bin-days": func(s *profileSettings, value string) error {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 || days > 365 {
+			return errors.New("bin-days must be a number of days from 1 to 365")
+		}
+		s.BinDays = days
+		return nil
+	},
+	"shiny-odds": func(s *profileSettings, value string) error {
+		odds, err := strconv.Atoi(strings.TrimPrefix(value, "1/"))
+		if err != nil || odds < 1 || odds > 65536 {
+			return errors.New("shiny-odds is the 1 in n chance of a shiny, n from 1 to 65536")
+		}
+		s.ShinyOdds = odds
+		return nil
+	},
+}
+
+// Directory holding every profile's save file, and the name of the last one used.
+func profilesDir() (string, error) {
+	dir, err := userDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pokedexcli", "profiles"), nil
+}
+
+func profileSaveFile(name string) (string, error) {
+	dir, err := profilesDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// The profile used last, so --profile only needs giving once.
+func lastProfile() string {
+	dir, err := profilesDir()
+	if err != nil {
+		return defaultProfile
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "current"))
+	name := strings.TrimSpace(string(data))
+	if err != nil || !profileNamePattern.MatchString(name) {
+		return defaultProfile
+	}
+	return name
+}
+
+func rememberProfile(name string) error {
+	dir, err := profilesDir()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, "current"), []byte(name+"\n"))
+}
+
+// Names of every profile with a save file, plus the current one.
+func listProfiles(current string) ([]string, error) {
+	dir, err := profilesDir()
+	if err != nil {
+		return nil, err
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, match := range matches {
+		names = append(names, strings.TrimSuffix(filepath.Base(match), ".json"))
+	}
+	if current != "" && !slices.Contains(names, current) {
+		names = append(names, current)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Save files from before profiles existed become the default profile.
+func adoptLegacySave(path string) error {
+	dir, err := userDataDir()
+	if err != nil {
+		return nil
+	}
+	legacy := filepath.Join(dir, "pokedexcli", "save.json")
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.Rename(legacy, path)
+}
+
+// Opens a profile's storage in place of the current one, flushing the old one first.
+// An explicit --save file takes the place of the profile's own.
+func (cfg *config) openProfile(name string) error {
+	path := cfg.saveFile
+	if path == "" && cfg.storageBackend == "file" {
+		var err error
+		if path, err = profileSaveFile(name); err != nil {
+			return err
+		}
+		if name == defaultProfile {
+			if err = adoptLegacySave(path); err != nil {
+				return err
+			}
+		}
+	}
+
+	opened, err := storage.Open(cfg.storageBackend, path)
+	if err != nil {
+		return err
+	}
+	if err = arrangeStorage(opened); err != nil {
+		opened.Close()
+		return err
+	}
+
+	if cfg.storage != nil {
+		if err = cfg.storage.Close(); err != nil {
+			opened.Close()
+			return err
+		}
+	}
+	cfg.history = newJournal(opened)
+	cfg.storage = cfg.history
+	cfg.profile = name
+
+	settings, err := cfg.settings()
+	if err != nil {
+		return err
+	}
+	if !cfg.outputSet {
+		cfg.output = cmp.Or(settings.Output, output.Text)
+	}
+	var location trainerLocation
+	if _, err = opened.Record(locationRecord, &location); err != nil {
+		return err
+	}
+	cfg.currentArea = location.Area
+
+	if err = cfg.emptyExpired(); err != nil {
+		return err
+	}
+	//Stamp new trainers so profile can show how long they've been playing,
+	//for everyone else opening the profile doesn't write the save
+	var stats trainerStats
+	if _, err = opened.Record(statsRecord, &stats); err != nil || !stats.Started.IsZero() {
+		return err
+	}
+	return cfg.updateStats(func(*trainerStats) {})
+}
+
+// The current trainer's settings.
+func (cfg *config) settings() (profileSettings, error) {
+	var settings profileSettings
+	_, err := cfg.storage.Record(settingsRecord, &settings)
+	return settings, err
+}
+
+// Applies change to the current trainer's stats and stores them.
+func (cfg *config) updateStats(change func(*trainerStats)) error {
+	var stats trainerStats
+	if _, err := cfg.storage.Record(statsRecord, &stats); err != nil {
+		return err
+	}
+	if stats.Started.IsZero() {
+		stats.Started = time.Now()
+	}
+	change(&stats)
+	return cfg.storage.SetRecord(statsRecord, stats)
+}
+
+// Manages trainer profiles, each with its own box, stats and settings
+func commandProfile(cfg *config, args []string, _ cmdFlags) (any, error) {
+	if len(args) == 0 {
+		return currentProfile(cfg)
+	}
+
+	switch args[0] {
+	case "list":
+		names, err := listProfiles(cfg.profile)
+		if err != nil {
+			return nil, err
+		}
+		return profileList{Current: cfg.profile, Profiles: names}, nil
+	case "new", "switch":
+		if len(args) < 2 {
+			return nil, errUsage
+		}
+		//Every profile would open the one -save file, so there is nothing to switch between
+		if cfg.saveFile != "" {
+			return nil, fmt.Errorf("Profiles can't be changed while -save %s is used instead of them", cfg.saveFile)
+		}
+		name := strings.ToLower(args[1])
+		if !profileNamePattern.MatchString(name) {
+			return nil, errors.New("Profile names are up to 32 letters, digits, - or _")
+		}
+
+		names, err := listProfiles(cfg.profile)
+		if err != nil {
+			return nil, err
+		}
+		exists := slices.Contains(names, name)
+		if args[0] == "new" && exists {
+			return nil, fmt.Errorf("Profile %s already exists, use 'profile switch %s'", name, name)
+		}
+		if args[0] == "switch" && !exists {
+			return nil, fmt.Errorf("No profile named %s, create it with 'profile new %s'", name, name)
+		}
+
+		if err = cfg.openProfile(name); err != nil {
+			return nil, err
+		}
+		//Only a profile with a save file of its own is there to come back to next time
+		path, err := profileSaveFile(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = os.Stat(path); err == nil {
+			if err = rememberProfile(name); err != nil {
+				return nil, err
+			}
+		}
+		return currentProfile(cfg)
+	case "set":
+		if len(args) < 3 {
+			return nil, errUsage
+		}
+		set, known := settingKeys[args[1]]
+		if !known {
+			return nil, fmt.Errorf("Unknown setting %q", args[1])
+		}
+
+		settings, err := cfg.settings()
+		if err != nil {
+			return nil, err
+		}
+		if err := set(&settings, args[2]); err != nil {
+			return nil, err
+		}
+		if err := cfg.storage.SetRecord(settingsRecord, settings); err != nil {
+			return nil, err
+		}
+		if args[1] == "output" && !cfg.outputSet {
+			cfg.output = settings.Output
+		}
+		return currentProfile(cfg)
+	}
+	return nil, errUsage
+}
+
+// Summary of the active profile.
+func currentProfile(cfg *config) (any, error) {
+	info := profileInfo{Name: cfg.profile, Pokemon: len(cfg.storage.List())}
+	if _, err := cfg.storage.Record(statsRecord, &info.Stats); err != nil {
+		return nil, err
+	}
+	var err error
+	if info.Settings, err = cfg.settings(); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
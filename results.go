@@ -0,0 +1,833 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Results returned by command callbacks. The output package renders them as
+// JSON/YAML/CSV from their fields, or as text through their Text method.
+
+// Summary of every command, from help.
+type commandList struct {
+	Commands []commandSummary `json:"commands"`
+}
+
+type commandSummary struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (l commandList) Text() string {
+	var b strings.Builder
+	b.WriteString("\nCommand list:\n")
+	for _, cmd := range l.Commands {
+		fmt.Fprintf(&b, "-%-10s %s\n", cmd.Name, cmd.Description)
+	}
+	b.WriteString("\nType 'help <command>' for usage and examples")
+	return b.String()
+}
+
+func (l commandList) Table() ([]string, [][]string) {
+	rows := make([][]string, 0, len(l.Commands))
+	for _, cmd := range l.Commands {
+		rows = append(rows, []string{cmd.Name, cmd.Description})
+	}
+	return []string{"name", "description"}, rows
+}
+
+// Detailed help for a single command, from help <command>.
+type commandDetail struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Usage       string            `json:"usage"`
+	Args        []commandArgument `json:"args"`
+	Flags       []commandArgument `json:"flags"`
+	Aliases     []string          `json:"aliases"`
+	Examples    []string          `json:"examples"`
+}
+
+type commandArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func newCommandDetail(cmd cliCommand) commandDetail {
+	detail := commandDetail{
+		Name:        cmd.name,
+		Description: cmd.description,
+		Usage:       cmd.usage,
+		Args:        []commandArgument{},
+		Flags:       []commandArgument{},
+		Aliases:     append([]string{}, cmd.aliases...),
+		Examples:    append([]string{}, cmd.examples...),
+	}
+	for _, arg := range cmd.args {
+		detail.Args = append(detail.Args, commandArgument{Name: arg.name, Description: arg.description})
+	}
+	for _, flag := range cmd.flags {
+		name := strings.TrimSpace("--" + flag.name + " " + flag.value)
+		detail.Flags = append(detail.Flags, commandArgument{Name: name, Description: flag.description})
+	}
+	return detail
+}
+
+func (d commandDetail) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n%s: %s\n", d.Name, d.Description)
+	fmt.Fprintf(&b, "\nUsage: %s\n", d.Usage)
+
+	if len(d.Args) > 0 {
+		b.WriteString("\nArguments:\n")
+		for _, arg := range d.Args {
+			fmt.Fprintf(&b, "  %-12s %s\n", arg.Name, arg.Description)
+		}
+	}
+	if len(d.Flags) > 0 {
+		b.WriteString("\nFlags:\n")
+		for _, flag := range d.Flags {
+			fmt.Fprintf(&b, "  %-20s %s\n", flag.Name, flag.Description)
+		}
+	}
+	if len(d.Aliases) > 0 {
+		fmt.Fprintf(&b, "\nAliases: %s\n", strings.Join(d.Aliases, ", "))
+	}
+	if len(d.Examples) > 0 {
+		b.WriteString("\nExamples:\n")
+		for _, example := range d.Examples {
+			fmt.Fprintf(&b, "  %s\n", example)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// A page of location areas, from mapf and mapb.
+type areaPage struct {
+	Areas []string `json:"areas"`
+}
+
+func (p areaPage) Text() string {
+	return "\nAreas:\n--------------\n" + strings.Join(p.Areas, "\n")
+}
+
+func (p areaPage) Table() ([]string, [][]string) {
+	return []string{"area"}, column(p.Areas)
+}
+
+// Pokemon found in an area, from explore.
+type areaPokemon struct {
+	Area    string   `json:"area"`
+	Version string   `json:"version,omitempty"`
+	Method  string   `json:"method,omitempty"`
+	Methods []string `json:"methods"`
+	Pokemon []string `json:"pokemon"`
+	// Pokemon only some of the area's versions have, with those versions
+	Exclusive map[string][]string `json:"exclusive,omitempty"`
+}
+
+func (a areaPokemon) Text() string {
+	title := "Local Pokemon"
+	if a.Version != "" {
+		title += " in " + a.Version
+	}
+	if a.Method != "" {
+		title += " by " + a.Method
+	}
+	title += ":"
+	lines := []string{"", title, strings.Repeat("-", len(title))}
+	for _, p := range a.Pokemon {
+		if versions, exclusive := a.Exclusive[p]; exclusive {
+			p = fmt.Sprintf("%-16s only in %s", p, strings.Join(versions, ", "))
+		}
+		lines = append(lines, p)
+	}
+	if len(a.Methods) > 1 {
+		lines = append(lines, "", "Found by: "+strings.Join(a.Methods, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (a areaPokemon) Table() ([]string, [][]string) {
+	rows := make([][]string, 0, len(a.Pokemon))
+	for _, p := range a.Pokemon {
+		rows = append(rows, []string{p, strings.Join(a.Exclusive[p], " ")})
+	}
+	return []string{"pokemon", "only_in"}, rows
+}
+
+// The game version encounters come from, from version.
+type versionResult struct {
+	Version      string   `json:"version"`
+	Area         string   `json:"area,omitempty"`
+	AreaVersions []string `json:"area_versions,omitempty"`
+}
+
+func (v versionResult) Text() string {
+	text := "Wild pokemon come from every game version, pick one with 'version <name>'"
+	if v.Version != "" {
+		text = fmt.Sprintf("Wild pokemon come from Pokemon %s", toDisplay(v.Version))
+	}
+	if v.Area != "" {
+		text += fmt.Sprintf("\n%s has encounters in: %s", v.Area, strings.Join(v.AreaVersions, ", "))
+	}
+	return text
+}
+
+// A cast or a surf that turned nothing up.
+type noEncounterResult struct {
+	Area   string `json:"area"`
+	Method string `json:"method"`
+}
+
+func (n noEncounterResult) Text() string {
+	if strings.HasSuffix(n.Method, "-rod") {
+		return "Not even a nibble!"
+	}
+	return fmt.Sprintf("Nothing turned up in %s", n.Area)
+}
+
+// Where the trainer is, from goto.
+type locationResult struct {
+	Area string `json:"area"`
+}
+
+func (l locationResult) Text() string {
+	return fmt.Sprintf("You are in %s", l.Area)
+}
+
+// Outcome of a throw, one of caught, close or escaped.
+type catchResult struct {
+	Pokemon string   `json:"pokemon"`
+	Level   int      `json:"level"`
+	OnlyIn  []string `json:"only_in,omitempty"`
+	Shiny   bool     `json:"shiny"`
+	Ball    string   `json:"ball"`
+	Left    int      `json:"balls_left"`
+	Outcome string   `json:"outcome"`
+	Caught  bool     `json:"caught"`
+	Shakes  int      `json:"shakes"`
+	Chance  float64  `json:"chance"`
+	ID      int      `json:"id,omitempty"`
+	Box     int      `json:"box,omitempty"`
+	// Experience each other party member got for the catch
+	Experience int       `json:"experience,omitempty"`
+	LevelUps   []levelUp `json:"level_ups,omitempty"`
+}
+
+func (c catchResult) Text() string {
+	text := fmt.Sprintf("A wild %s (level %d) appeared!\n", c.Pokemon, c.Level)
+	if len(c.OnlyIn) > 0 {
+		text += fmt.Sprintf("It's only found here in %s!\n", strings.Join(c.OnlyIn, ", "))
+	}
+	if c.Shiny {
+		text += "*** It's shiny! ***\n"
+	}
+	text += fmt.Sprintf("Throwing %s! (%d left)", toDisplay(c.Ball), c.Left) + strings.Repeat("\n.", c.Shakes) + "\n"
+	switch c.Outcome {
+	case "caught":
+		text += fmt.Sprintf("%v was caught! (#%d)", c.Pokemon, c.ID)
+		if c.Box != partyBox {
+			text += fmt.Sprintf("\nYour party is full, it was sent to box %d", c.Box)
+		}
+		if c.Experience > 0 {
+			text += fmt.Sprintf("\nYour party gained %d exp!", c.Experience)
+		}
+		for _, up := range c.LevelUps {
+			text += "\n" + up.Text()
+		}
+		return text
+	case "close":
+		return text + fmt.Sprintf("%v escaped! So close!", c.Pokemon)
+	}
+	if c.Shakes == 0 {
+		return text + fmt.Sprintf("%v immediately escaped!", c.Pokemon)
+	}
+	return text + fmt.Sprintf("%v broke free!", c.Pokemon)
+}
+
+// A level a caught pokemon grew.
+type levelUp struct {
+	ID    int        `json:"id"`
+	Name  string     `json:"name"`
+	Level int        `json:"level"`
+	Gains statSpread `json:"gains"`
+	// Species it evolved into when this level was enough
+	Evolved string `json:"evolved_into,omitempty"`
+}
+
+func (l levelUp) Text() string {
+	text := fmt.Sprintf("%s grew to level %d!", l.Name, l.Level)
+	var gains []string
+	for _, stat := range statOrder {
+		if gain := *l.Gains.Stat(stat); gain != 0 {
+			gains = append(gains, fmt.Sprintf("%s +%d", strings.ReplaceAll(stat, "-", " "), gain))
+		}
+	}
+	if len(gains) > 0 {
+		text += " (" + strings.Join(gains, ", ") + ")"
+	}
+	if l.Evolved != "" {
+		text += fmt.Sprintf("\nCongratulations! Your %s evolved into %s!", l.Name, l.Evolved)
+	}
+	return text
+}
+
+// A pokemon that evolved, from evolve.
+type evolveResult struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	From string `json:"from"`
+	Into string `json:"into"`
+}
+
+func (e evolveResult) Text() string {
+	return fmt.Sprintf("What? %s is evolving!\nCongratulations! Your %s evolved into %s! (#%d)", e.Name, e.Name, e.Into, e.ID)
+}
+
+type releaseResult struct {
+	Released []pokedexEntry `json:"released"`
+	Days     int            `json:"days"`
+}
+
+func (r releaseResult) Text() string {
+	lines := []string{}
+	for _, p := range r.Released {
+		lines = append(lines, fmt.Sprintf("%v was released. Bye bye!", p.name()))
+	}
+	lines = append(lines, fmt.Sprintf("Changed your mind? It stays in the bin for %d days, see 'bin'", r.Days))
+	return strings.Join(lines, "\n")
+}
+
+func (r releaseResult) Table() ([]string, [][]string) {
+	return pokedexList{Pokemon: r.Released}.Table()
+}
+
+// The recycle bin, from bin.
+type binList struct {
+	Days    int            `json:"days"`
+	Pokemon []binListEntry `json:"pokemon"`
+}
+
+type binListEntry struct {
+	pokedexEntry
+	ReleasedAt time.Time `json:"released_at"`
+	DaysLeft   int       `json:"days_left"`
+}
+
+func (l binList) Text() string {
+	if len(l.Pokemon) == 0 {
+		return "The bin is empty"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Released pokemon, kept for %d days:", l.Days)
+	for _, p := range l.Pokemon {
+		fmt.Fprintf(&b, "\n- %-32s released %s, %d days left", p.Text(), p.ReleasedAt.Local().Format("2006-01-02 15:04"), p.DaysLeft)
+	}
+	return b.String()
+}
+
+func (l binList) Table() ([]string, [][]string) {
+	rows := make([][]string, 0, len(l.Pokemon))
+	for _, p := range l.Pokemon {
+		rows = append(rows, []string{
+			strconv.Itoa(p.ID), strconv.Itoa(p.DexID), p.Species, p.Nickname,
+			formatTime(p.ReleasedAt), strconv.Itoa(p.DaysLeft),
+		})
+	}
+	return []string{"id", "dex_id", "species", "nickname", "released_at", "days_left"}, rows
+}
+
+// What undo took back.
+type undoResult struct {
+	Command string    `json:"command"`
+	At      time.Time `json:"at"`
+}
+
+func (u undoResult) Text() string {
+	return fmt.Sprintf("Undid '%s' from %s", u.Command, u.At.Local().Format("2006-01-02 15:04"))
+}
+
+// Stats of a caught pokemon, from inspect.
+type pokemonInfo struct {
+	ID       int         `json:"id"`
+	Name     string      `json:"name"`
+	Nickname string      `json:"nickname,omitempty"`
+	CaughtAt time.Time   `json:"caught_at"`
+	Area     string      `json:"area,omitempty"`
+	Ball     string      `json:"ball"`
+	Shiny    bool        `json:"shiny"`
+	Sprite   string      `json:"sprite,omitempty"`
+	Level    int         `json:"level,omitempty"`
+	Exp      int         `json:"exp,omitempty"`
+	Nature   string      `json:"nature,omitempty"`
+	Height   int         `json:"height"`
+	Weight   int         `json:"weight"`
+	Stats    []statValue `json:"stats"`
+	Types    []string    `json:"types"`
+}
+
+// A stat's base value and, for catches with a level and IVs, its actual value.
+type statValue struct {
+	Name  string `json:"name"`
+	Base  int    `json:"base"`
+	Value int    `json:"value,omitempty"`
+	IV    int    `json:"iv"`
+	EV    int    `json:"ev"`
+}
+
+func newPokemonInfo(c *Caught) pokemonInfo {
+	p := c.Pokemon
+	info := pokemonInfo{
+		ID:       c.ID,
+		Name:     p.Name,
+		Nickname: c.Nickname,
+		CaughtAt: c.CaughtAt,
+		Area:     c.Area,
+		Ball:     c.Ball,
+		Shiny:    c.Shiny,
+		Sprite:   p.Sprites.FrontDefault,
+		Level:    c.Level,
+		Exp:      c.Experience,
+		Nature:   c.Nature,
+		Height:   p.Height,
+		Weight:   p.Weight,
+		Stats:    []statValue{},
+		Types:    []string{},
+	}
+	if c.Shiny {
+		info.Sprite = p.Sprites.FrontShiny
+	}
+	computed, known := computeStats(c)
+	for _, stat := range p.Stats {
+		value := statValue{Name: stat.Stat.Name, Base: stat.BaseStat}
+		if v := computed.Stat(stat.Stat.Name); known && v != nil {
+			value.Value = *v
+			value.IV = *c.IVs.Stat(stat.Stat.Name)
+			value.EV = *c.EVs.Stat(stat.Stat.Name)
+		}
+		info.Stats = append(info.Stats, value)
+	}
+	for _, t := range p.Types {
+		info.Types = append(info.Types, t.Type.Name)
+	}
+	return info
+}
+
+func (p pokemonInfo) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#%d\n", p.ID)
+	if p.Shiny {
+		fmt.Fprintln(&b, "Name: ", p.Name, "(shiny)")
+	} else {
+		fmt.Fprintln(&b, "Name: ", p.Name)
+	}
+	if p.Nickname != "" {
+		fmt.Fprintln(&b, "Nickname: ", p.Nickname)
+	}
+	fmt.Fprintln(&b, "Caught: ", describeCatch(p.CaughtAt, p.Area, p.Ball))
+	if p.Sprite != "" {
+		fmt.Fprintln(&b, "Sprite: ", p.Sprite)
+	}
+	if p.Level > 0 {
+		fmt.Fprintln(&b, "Level: ", p.Level)
+	}
+	if p.Exp > 0 {
+		fmt.Fprintln(&b, "Exp: ", p.Exp)
+	}
+	if effect, known := natures[p.Nature]; known {
+		if effect.up != "" {
+			fmt.Fprintf(&b, "Nature:  %s (+%s, -%s)\n", p.Nature, strings.ReplaceAll(effect.up, "-", " "), strings.ReplaceAll(effect.down, "-", " "))
+		} else {
+			fmt.Fprintln(&b, "Nature: ", p.Nature)
+		}
+	}
+	fmt.Fprintln(&b, "Height: ", p.Height)
+	fmt.Fprintln(&b, "Weight: ", p.Weight)
+	if p.Level > 0 && len(p.Stats) > 0 && p.Stats[0].Value > 0 {
+		fmt.Fprintf(&b, "%-20s %5s %5s %4s %4s\n", "Stats:", "base", "stat", "IV", "EV")
+		for _, stat := range p.Stats {
+			fmt.Fprintf(&b, " -%-18s %5d %5d %4d %4d\n", strings.ReplaceAll(stat.Name, "-", " ")+":", stat.Base, stat.Value, stat.IV, stat.EV)
+		}
+	} else {
+		fmt.Fprintln(&b, "Stats: ")
+		for _, stat := range p.Stats {
+			fmt.Fprintf(&b, " -%s:  %d\n", strings.ReplaceAll(stat.Name, "-", " "), stat.Base)
+		}
+	}
+	fmt.Fprintln(&b, "Types: ")
+	for _, t := range p.Types {
+		fmt.Fprintln(&b, " - ", t)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Caught pokemon, from pokedex.
+type pokedexList struct {
+	Pokemon []pokedexEntry `json:"pokemon"`
+}
+
+type pokedexEntry struct {
+	ID       int       `json:"id"`
+	DexID    int       `json:"dex_id"`
+	Species  string    `json:"species"`
+	Nickname string    `json:"nickname,omitempty"`
+	CaughtAt time.Time `json:"caught_at"`
+	Area     string    `json:"area,omitempty"`
+	Ball     string    `json:"ball"`
+	Box      int       `json:"box"`
+	Slot     int       `json:"slot"`
+	Level    int       `json:"level,omitempty"`
+	Shiny    bool      `json:"shiny,omitempty"`
+}
+
+func newPokedexEntry(c *Caught) pokedexEntry {
+	return pokedexEntry{
+		ID:       c.ID,
+		DexID:    c.Pokemon.ID,
+		Species:  c.Pokemon.Name,
+		Nickname: c.Nickname,
+		CaughtAt: c.CaughtAt,
+		Area:     c.Area,
+		Ball:     c.Ball,
+		Box:      c.Box,
+		Slot:     c.Slot,
+		Level:    c.Level,
+		Shiny:    c.Shiny,
+	}
+}
+
+// Nickname if it has one, otherwise the species name.
+func (e pokedexEntry) name() string {
+	return cmp.Or(e.Nickname, e.Species)
+}
+
+func (e pokedexEntry) Text() string {
+	line := fmt.Sprintf("#%-4d %s", e.ID, e.Species)
+	if e.Nickname != "" {
+		line += fmt.Sprintf(" %q", e.Nickname)
+	}
+	if e.Level > 0 {
+		line += fmt.Sprintf(" Lv.%d", e.Level)
+	}
+	if e.Shiny {
+		line += " (shiny)"
+	}
+	return line
+}
+
+func (l pokedexList) Text() string {
+	var b strings.Builder
+	b.WriteString("Current Box:")
+	for _, pokemon := range l.Pokemon {
+		fmt.Fprintf(&b, "\n- %-32s %s", pokemon.Text(), describeSlot(pokemon.Box, pokemon.Slot))
+	}
+	return b.String()
+}
+
+func (l pokedexList) Table() ([]string, [][]string) {
+	rows := make([][]string, 0, len(l.Pokemon))
+	for _, e := range l.Pokemon {
+		rows = append(rows, []string{
+			strconv.Itoa(e.ID), strconv.Itoa(e.DexID), e.Species, e.Nickname,
+			formatTime(e.CaughtAt), e.Area, e.Ball, strconv.Itoa(e.Box), strconv.Itoa(e.Slot), strconv.FormatBool(e.Shiny),
+		})
+	}
+	return []string{"id", "dex_id", "species", "nickname", "caught_at", "area", "ball", "box", "slot", "shiny"}, rows
+}
+
+// The party or a PC box, from party and box.
+type boxList struct {
+	Name    string         `json:"name"`
+	Box     int            `json:"box"`
+	Pokemon []pokedexEntry `json:"pokemon"`
+}
+
+func newBoxList(name string, box int, contents []*Caught) boxList {
+	list := boxList{Name: name, Box: box, Pokemon: []pokedexEntry{}}
+	for _, c := range contents {
+		list.Pokemon = append(list.Pokemon, newPokedexEntry(c))
+	}
+	return list
+}
+
+func (l boxList) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%d/%d):", l.Name, len(l.Pokemon), capacity(l.Box))
+	if len(l.Pokemon) == 0 {
+		b.WriteString("\n  empty")
+	}
+	for _, pokemon := range l.Pokemon {
+		fmt.Fprintf(&b, "\n %2d. %s", pokemon.Slot, pokemon.Text())
+	}
+	return b.String()
+}
+
+func (l boxList) Table() ([]string, [][]string) {
+	return pokedexList{Pokemon: l.Pokemon}.Table()
+}
+
+// Where a pokemon ended up, from deposit, withdraw and move.
+type moveResult struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Box  int    `json:"box"`
+	Slot int    `json:"slot"`
+}
+
+func newMoveResult(c *Caught) moveResult {
+	return moveResult{ID: c.ID, Name: c.Name(), Box: c.Box, Slot: c.Slot}
+}
+
+func (m moveResult) Text() string {
+	return fmt.Sprintf("%s is now in %s", m.Name, describeSlot(m.Box, m.Slot))
+}
+
+// The active profile, from profile.
+type profileInfo struct {
+	Name     string          `json:"name"`
+	Pokemon  int             `json:"pokemon"`
+	Stats    trainerStats    `json:"stats"`
+	Settings profileSettings `json:"settings"`
+}
+
+func (p profileInfo) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Trainer: %s\n", p.Name)
+	if !p.Stats.Started.IsZero() {
+		fmt.Fprintf(&b, "Playing since: %s\n", p.Stats.Started.Local().Format("2006-01-02"))
+	}
+	fmt.Fprintf(&b, "Pokemon: %d\n", p.Pokemon)
+	fmt.Fprintf(&b, "Pokeballs thrown: %d (%d caught, %d escaped)\n", p.Stats.Throws, p.Stats.Caught, p.Stats.Escaped)
+	fmt.Fprintf(&b, "Released: %d", p.Stats.Released)
+	if len(p.Stats.Shinies) > 0 {
+		var shinies []string
+		for _, species := range slices.Sorted(maps.Keys(p.Stats.Shinies)) {
+			shinies = append(shinies, fmt.Sprintf("%s %d", species, p.Stats.Shinies[species]))
+		}
+		fmt.Fprintf(&b, "\nShinies caught: %s", strings.Join(shinies, ", "))
+	}
+	if p.Settings.Output != "" {
+		fmt.Fprintf(&b, "\nOutput: %s", p.Settings.Output)
+	}
+	if p.Settings.BinDays != 0 {
+		fmt.Fprintf(&b, "\nBin keeps released pokemon for: %d days", p.Settings.BinDays)
+	}
+	if p.Settings.ShinyOdds != 0 {
+		fmt.Fprintf(&b, "\nShiny odds: 1/%d", p.Settings.ShinyOdds)
+	}
+	return b.String()
+}
+
+// Every profile, from profile list.
+type profileList struct {
+	Current  string   `json:"current"`
+	Profiles []string `json:"profiles"`
+}
+
+func (l profileList) Text() string {
+	var b strings.Builder
+	b.WriteString("Profiles:")
+	for _, name := range l.Profiles {
+		marker := " "
+		if name == l.Current {
+			marker = "*"
+		}
+		fmt.Fprintf(&b, "\n %s %s", marker, name)
+	}
+	return b.String()
+}
+
+func (l profileList) Table() ([]string, [][]string) {
+	rows := make([][]string, 0, len(l.Profiles))
+	for _, name := range l.Profiles {
+		rows = append(rows, []string{name, strconv.FormatBool(name == l.Current)})
+	}
+	return []string{"profile", "current"}, rows
+}
+
+// A written report, from export.
+type exportResult struct {
+	Format  string `json:"format"`
+	File    string `json:"file"`
+	Pokemon int    `json:"pokemon"`
+}
+
+func (e exportResult) Text() string {
+	return fmt.Sprintf("Exported %d pokemon to %s", e.Pokemon, e.File)
+}
+
+type importResult struct {
+	Pokemon []pokedexEntry `json:"pokemon"`
+}
+
+func (r importResult) Text() string {
+	lines := []string{fmt.Sprintf("Imported %d pokemon:", len(r.Pokemon))}
+	for _, p := range r.Pokemon {
+		lines = append(lines, p.Text())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r importResult) Table() ([]string, [][]string) {
+	return pokedexList{Pokemon: r.Pokemon}.Table()
+}
+
+// Items in the bag, from bag.
+type bagList struct {
+	Items []bagItem `json:"items"`
+}
+
+type bagItem struct {
+	Name   string `json:"name"`
+	Pocket string `json:"pocket"`
+	Count  int    `json:"count"`
+}
+
+// Balls first in the order they get better, then other items by name.
+func newBagList(b bag) bagList {
+	list := bagList{Items: []bagItem{}}
+	for _, ball := range ballOrder {
+		if b[ball] > 0 {
+			list.Items = append(list.Items, bagItem{Name: ball, Pocket: "balls", Count: b[ball]})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(b)) {
+		if !slices.Contains(ballOrder, name) && b[name] > 0 {
+			list.Items = append(list.Items, bagItem{Name: name, Pocket: "items", Count: b[name]})
+		}
+	}
+	return list
+}
+
+func (l bagList) Text() string {
+	if len(l.Items) == 0 {
+		return "Your bag is empty"
+	}
+	var b strings.Builder
+	pocket := ""
+	for _, item := range l.Items {
+		if item.Pocket != pocket {
+			if pocket != "" {
+				b.WriteString("\n")
+			}
+			pocket = item.Pocket
+			fmt.Fprintf(&b, "%s:", strings.ToUpper(pocket[:1])+pocket[1:])
+		}
+		fmt.Fprintf(&b, "\n- %-16s x%d", toDisplay(item.Name), item.Count)
+	}
+	return b.String()
+}
+
+func (l bagList) Table() ([]string, [][]string) {
+	rows := make([][]string, 0, len(l.Items))
+	for _, item := range l.Items {
+		rows = append(rows, []string{item.Name, item.Pocket, strconv.Itoa(item.Count)})
+	}
+	return []string{"name", "pocket", "count"}, rows
+}
+
+// What using an item did, from use.
+type useResult struct {
+	Item    string `json:"item"`
+	ID      int    `json:"id"`
+	Message string `json:"message"`
+	Left    int    `json:"left"`
+}
+
+func (u useResult) Text() string {
+	return fmt.Sprintf("Used a %s. %s (%d left)", toDisplay(u.Item), u.Message, u.Left)
+}
+
+// What save verify found.
+type saveReport struct {
+	File    string `json:"file"`
+	Version int    `json:"version"`
+	Current int    `json:"current_version"`
+	Pokemon int    `json:"pokemon"`
+	// How the file is protected: checksum ok, encrypted, none for old saves, or failed.
+	Integrity string   `json:"integrity"`
+	Valid     bool     `json:"valid"`
+	Problems  []string `json:"problems"`
+}
+
+func (r saveReport) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: save version %d", r.File, r.Version)
+	if r.Version < r.Current {
+		fmt.Fprintf(&b, ", migrates to %d on load", r.Current)
+	}
+	if r.Integrity != "" {
+		fmt.Fprintf(&b, "\nIntegrity: %s", r.Integrity)
+	}
+	if r.Valid {
+		fmt.Fprintf(&b, "\nValid, %d pokemon", r.Pokemon)
+		return b.String()
+	}
+	b.WriteString("\nNot valid:")
+	for _, problem := range r.Problems {
+		b.WriteString("\n- " + problem)
+	}
+	return b.String()
+}
+
+// One row per problem, or a single row with none, each carrying the verdict.
+func (r saveReport) Table() ([]string, [][]string) {
+	header := []string{"file", "version", "current_version", "integrity", "valid", "pokemon", "problem"}
+	row := []string{r.File, strconv.Itoa(r.Version), strconv.Itoa(r.Current), r.Integrity, strconv.FormatBool(r.Valid), strconv.Itoa(r.Pokemon)}
+	if len(r.Problems) == 0 {
+		return header, [][]string{append(row, "")}
+	}
+	rows := make([][]string, 0, len(r.Problems))
+	for _, problem := range r.Problems {
+		rows = append(rows, append(slices.Clone(row), problem))
+	}
+	return header, rows
+}
+
+// A save that doesn't verify fails the command, after the report is shown.
+func (r saveReport) failed() error {
+	if r.Valid {
+		return nil
+	}
+	return fmt.Errorf("%s is not a valid save file", r.File)
+}
+
+// Catch time, place and ball as one line, leaving out whatever is unknown.
+func describeCatch(at time.Time, area, ball string) string {
+	parts := []string{}
+	if !at.IsZero() {
+		parts = append(parts, at.Local().Format("2006-01-02 15:04"))
+	}
+	if area != "" {
+		parts = append(parts, "in "+area)
+	}
+	if ball != "" {
+		parts = append(parts, "with a "+ball)
+	}
+	if len(parts) == 0 {
+		return "unknown"
+	}
+	return strings.Join(parts, " ")
+}
+
+// RFC 3339 time for tables, empty when unknown.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// One-column table rows for string lists.
+func column(values []string) [][]string {
+	rows := make([][]string, 0, len(values))
+	for _, v := range values {
+		rows = append(rows, []string{v})
+	}
+	return rows
+}
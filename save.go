@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Version written into new save files, bump it whenever saveFile changes shape
+// and register a migration from the previous version in migrate.go.
+const saveVersion = 4
+
+// A trainer's box and records, written by the file storage backend inside a sealedSave.
+type saveFile struct {
+	Version int                        `json:"version"`
+	NextID  int                        `json:"next_id,omitempty"`
+	Box     []*Caught                  `json:"box"`
+	Records map[string]json.RawMessage `json:"records,omitempty"`
+}
+
+// Where per-user application data lives: $XDG_DATA_HOME or the platform's equivalent.
+// The standard library only knows about config and cache directories.
+func userDataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return dir, nil
+	}
+	switch runtime.GOOS {
+	case "windows":
+		if dir := os.Getenv("LocalAppData"); dir != "" {
+			return dir, nil
+		}
+		return "", errors.New("%LocalAppData% is not set")
+	case "darwin", "ios":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, "Library", "Application Support"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+// Writes to a temporary file next to path and renames it over path,
+// so a crash leaves either the old or the new file but never half of one.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	//Clean up on any failure, after a successful rename this is a no-op
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
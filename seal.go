@@ -0,0 +1,334 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// First save version written sealed, older files load without a check.
+const sealedSince = 3
+
+// Environment variable holding the passphrase of encrypted saves, asked for when unset.
+const passphraseEnv = "POKEDEX_PASSPHRASE"
+
+// PBKDF2 rounds for new passphrase keys
+const passphraseIterations = 600_000
+
+var (
+	errTampered      = errors.New("failed its integrity check, it was changed outside the Pokedex or is damaged")
+	errBadPassphrase = errors.New("can't be decrypted, the passphrase is wrong or the file was changed outside the Pokedex")
+	errUnsealed      = errors.New("was sealed before but isn't anymore, it may have been changed outside the Pokedex. Start with -accept-unsealed if you put it there yourself")
+	errUnencrypted   = errors.New("was encrypted before but isn't anymore, it may have been changed outside the Pokedex. Start with -accept-unsealed if you put it there yourself, it is encrypted again")
+)
+
+// Set by -accept-unsealed: load saves that lost their seal, after a restore from an old backup for example
+var acceptUnsealed bool
+
+// On-disk form of a save from version 3 on. The save inside is either stored
+// as is with an HMAC of it, or encrypted with a key from the trainer's passphrase.
+type sealedSave struct {
+	Version    int             `json:"version"`
+	Checksum   string          `json:"checksum,omitempty"`
+	Encryption *saveEncryption `json:"encryption,omitempty"`
+	Save       json.RawMessage `json:"save,omitempty"`
+	Sealed     []byte          `json:"sealed,omitempty"`
+}
+
+// How an encrypted save was sealed, everything needed to open it but the passphrase.
+type saveEncryption struct {
+	Cipher     string `json:"cipher"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+}
+
+// Key derived from a passphrase, kept by the file backend so saving doesn't derive it again.
+type saveKey struct {
+	key        []byte
+	salt       []byte
+	iterations int
+}
+
+func newSaveKey(passphrase string) (*saveKey, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return &saveKey{
+		key:        pbkdf2SHA256([]byte(passphrase), salt, passphraseIterations, 32),
+		salt:       salt,
+		iterations: passphraseIterations,
+	}, nil
+}
+
+// PBKDF2 with HMAC-SHA256 (RFC 8018), the standard library only has it from Go 1.24.
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var key []byte
+	for block := uint32(1); len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(binary.BigEndian.AppendUint32(nil, block))
+		u := prf.Sum(nil)
+		t := append([]byte(nil), u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
+
+// Per-user secret plain saves are signed with. Only its owner can read it,
+// so someone else on the machine can change a save but can't sign it again.
+var checksumKey = sync.OnceValues(func() ([]byte, error) {
+	dir, err := userDataDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, "pokedexcli", "save.key")
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return hex.DecodeString(strings.TrimSpace(string(data)))
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	key := make([]byte, 32)
+	if _, err = rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	//Exclusive create, so two sessions starting at once don't end up with different keys
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, fs.ErrExist) {
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return hex.DecodeString(strings.TrimSpace(string(data)))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err = f.WriteString(hex.EncodeToString(key) + "\n"); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return key, f.Close()
+})
+
+// How a save was sealed, see sealMarker
+const (
+	sealChecksum  = "checksum"
+	sealEncrypted = "encrypted"
+)
+
+// How each save was last written, checksum or encrypted, by absolute path. Kept next to
+// save.key, so a save swapped for an unsealed or unencrypted file is noticed.
+func sealMarkersPath() (string, error) {
+	dir, err := userDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pokedexcli", "sealed.json"), nil
+}
+
+func sealMarkers() (map[string]string, error) {
+	path, err := sealMarkersPath()
+	if err != nil {
+		return nil, err
+	}
+	markers := map[string]string{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return markers, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &markers); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return markers, nil
+}
+
+// How the save at path was last sealed, empty if it never was.
+func sealMarker(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	markers, err := sealMarkers()
+	if err != nil {
+		return "", err
+	}
+	return markers[abs], nil
+}
+
+func setSealMarker(path, seal string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	markers, err := sealMarkers()
+	if err != nil {
+		return err
+	}
+	if markers[abs] == seal {
+		return nil
+	}
+	markers[abs] = seal
+	data, err := json.MarshalIndent(markers, "", "  ")
+	if err != nil {
+		return err
+	}
+	markersPath, err := sealMarkersPath()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(markersPath, data)
+}
+
+func checksum(data []byte) (string, error) {
+	key, err := checksumKey()
+	if err != nil {
+		return "", fmt.Errorf("reading the save key: %w", err)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Wraps a save for writing, encrypted if there is a key, otherwise with its checksum.
+func sealSave(save []byte, key *saveKey) ([]byte, error) {
+	sealed := sealedSave{Version: saveVersion}
+	if key == nil {
+		sum, err := checksum(save)
+		if err != nil {
+			return nil, err
+		}
+		sealed.Checksum, sealed.Save = sum, save
+		return json.Marshal(sealed)
+	}
+
+	gcm, err := newGCM(key.key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed.Encryption = &saveEncryption{
+		Cipher:     "aes-256-gcm",
+		KDF:        "pbkdf2-sha256",
+		Iterations: key.iterations,
+		Salt:       key.salt,
+		Nonce:      nonce,
+	}
+	sealed.Sealed = gcm.Seal(nil, nonce, save, nil)
+	return json.Marshal(sealed)
+}
+
+// Checks or decrypts a save file, returning the save inside and the key it was encrypted with.
+// Files from before sealing come back as they are.
+func unsealSave(path string, data []byte) ([]byte, *saveKey, error) {
+	version, err := saveVersionOf(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if version < sealedSince {
+		return data, nil, nil
+	}
+	if version > saveVersion {
+		return nil, nil, fmt.Errorf("is from a newer version of the Pokedex (save version %d)", version)
+	}
+
+	var sealed sealedSave
+	if err = json.Unmarshal(data, &sealed); err != nil {
+		return nil, nil, err
+	}
+
+	if sealed.Encryption == nil {
+		sum, err := checksum(sealed.Save)
+		if err != nil {
+			return nil, nil, err
+		}
+		if sealed.Checksum == "" || !hmac.Equal([]byte(sum), []byte(sealed.Checksum)) {
+			return nil, nil, errTampered
+		}
+		return sealed.Save, nil, nil
+	}
+
+	enc := sealed.Encryption
+	if enc.Cipher != "aes-256-gcm" || enc.KDF != "pbkdf2-sha256" {
+		return nil, nil, fmt.Errorf("is encrypted with %s/%s, which this Pokedex doesn't know", enc.Cipher, enc.KDF)
+	}
+	passphrase, err := askPassphrase(fmt.Sprintf("Passphrase for %s:", path))
+	if err != nil {
+		return nil, nil, err
+	}
+	key := &saveKey{
+		key:        pbkdf2SHA256([]byte(passphrase), enc.Salt, enc.Iterations, 32),
+		salt:       enc.Salt,
+		iterations: enc.Iterations,
+	}
+	gcm, err := newGCM(key.key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(enc.Nonce) != gcm.NonceSize() {
+		return nil, nil, errBadPassphrase
+	}
+	save, err := gcm.Open(nil, enc.Nonce, sealed.Sealed, nil)
+	if err != nil {
+		return nil, nil, errBadPassphrase
+	}
+	return save, key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Passphrase from the environment, or typed in. The standard library can't turn
+// off echo, so use the environment variable where someone could be watching.
+func askPassphrase(prompt string) (string, error) {
+	if passphrase := os.Getenv(passphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	fmt.Fprintf(os.Stderr, "%s ", prompt)
+	line, ok := readAnswer()
+	if !ok || line == "" {
+		return "", fmt.Errorf("no passphrase given, type it in or set %s", passphraseEnv)
+	}
+	return line, nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// Points the data directory, and with it the seal markers, at a fresh temporary one.
+// The checksum key is read once per process, so it stays whatever the first test made.
+func useTempDataDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	return dir
+}
+
+// Key like newSaveKey makes, with few iterations so tests stay fast.
+func testSaveKey(passphrase string) *saveKey {
+	salt := []byte("0123456789abcdef")
+	return &saveKey{key: pbkdf2SHA256([]byte(passphrase), salt, 1000, 32), salt: salt, iterations: 1000}
+}
+
+const testSave = `{"version":4,"box":[{"id":1,"ball":"poke-ball","box":1,"slot":1,"level":5,"nature":"bold","pokemon":{"id":25,"name":"pikachu"}}]}`
+
+func TestPBKDF2SHA256(t *testing.T) {
+	//Test vector from RFC 7914, section 11
+	want := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+	if got := hex.EncodeToString(pbkdf2SHA256([]byte("passwd"), []byte("salt"), 1, 64)); got != want {
+		t.Errorf("pbkdf2SHA256 = %s, want %s", got, want)
+	}
+}
+
+func TestSealRoundTrip(t *testing.T) {
+	useTempDataDir(t)
+	t.Setenv(passphraseEnv, "pikachu")
+
+	for _, key := range []*saveKey{nil, testSaveKey("pikachu")} {
+		sealed, err := sealSave([]byte(testSave), key)
+		if err != nil {
+			t.Fatalf("sealSave: %v", err)
+		}
+		save, gotKey, err := unsealSave("test.json", sealed)
+		if err != nil {
+			t.Fatalf("unsealSave: %v", err)
+		}
+		if string(save) != testSave {
+			t.Errorf("unsealed %s, want %s", save, testSave)
+		}
+		if (key == nil) != (gotKey == nil) {
+			t.Errorf("key after unsealing = %v, sealed with %v", gotKey, key)
+		}
+	}
+}
+
+func TestUnsealTampered(t *testing.T) {
+	useTempDataDir(t)
+
+	sealed, err := sealSave([]byte(testSave), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file sealedSave
+	if err = json.Unmarshal(sealed, &file); err != nil {
+		t.Fatal(err)
+	}
+	file.Save = json.RawMessage(`{"version":4,"box":[]}`)
+	tampered, err := json.Marshal(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = unsealSave("test.json", tampered); !errors.Is(err, errTampered) {
+		t.Errorf("changed save: err = %v, want %v", err, errTampered)
+	}
+
+	file.Checksum = ""
+	unsigned, err := json.Marshal(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = unsealSave("test.json", unsigned); !errors.Is(err, errTampered) {
+		t.Errorf("checksum taken off: err = %v, want %v", err, errTampered)
+	}
+}
+
+func TestUnsealWrongPassphrase(t *testing.T) {
+	useTempDataDir(t)
+	sealed, err := sealSave([]byte(testSave), testSaveKey("pikachu"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(passphraseEnv, "raichu")
+	if _, _, err = unsealSave("test.json", sealed); !errors.Is(err, errBadPassphrase) {
+		t.Errorf("err = %v, want %v", err, errBadPassphrase)
+	}
+}
+
+// A save that was sealed before and turns up without it, as an old version or
+// unencrypted, must neither load nor verify.
+func TestDowngradedSave(t *testing.T) {
+	plain, err := sealSave([]byte(testSave), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		marker string
+		file   []byte
+		err    error
+	}{
+		{name: "sealed save as version 2", marker: sealChecksum, file: []byte(`{"version":2,"box":[{"id":1,"pokemon":{"id":25,"name":"pikachu"}}]}`), err: errUnsealed},
+		{name: "encrypted save as version 2", marker: sealEncrypted, file: []byte(`{"version":2,"box":[]}`), err: errUnencrypted},
+		{name: "encrypted save with a checksum", marker: sealEncrypted, file: plain, err: errUnencrypted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := useTempDataDir(t)
+			path := filepath.Join(dir, "save.json")
+			if err := os.WriteFile(path, tt.file, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := setSealMarker(path, tt.marker); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := openFileStorage(path); !errors.Is(err, tt.err) {
+				t.Errorf("loading: err = %v, want %v", err, tt.err)
+			}
+			report, err := verifySave(path)
+			if err != nil {
+				t.Fatalf("verifySave: %v", err)
+			}
+			if report.Valid || len(report.Problems) == 0 {
+				t.Errorf("verify passed it: %+v", report)
+			}
+		})
+	}
+}
+
+func TestSealMarkerFollowsSaves(t *testing.T) {
+	dir := useTempDataDir(t)
+	path := filepath.Join(dir, "save.json")
+
+	storage, err := openFileStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = storage.SetRecord(statsRecord, map[string]int{"thrown": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if seal, _ := sealMarker(path); seal != sealChecksum {
+		t.Errorf("marker after saving = %q, want %q", seal, sealChecksum)
+	}
+
+	report, err := verifySave(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Valid || report.Integrity != "checksum ok" || !slices.Equal(report.Problems, []string{}) {
+		t.Errorf("verify of a fresh save: %+v", report)
+	}
+}
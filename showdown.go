@@ -0,0 +1,395 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Showdown's short stat names, in the order it writes them
+var showdownStats = []struct {
+	short string
+	name  string
+}{
+	{"HP", "hp"},
+	{"Atk", "attack"},
+	{"Def", "defense"},
+	{"SpA", "special-attack"},
+	{"SpD", "special-defense"},
+	{"Spe", "speed"},
+}
+
+// Showdown writes display names, PokeAPI wants slugs: "Mr. Mime" is mr-mime.
+func toSlug(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.NewReplacer(".", "", "'", "", ":", "", "’", "").Replace(name)
+	return strings.Join(strings.Fields(name), "-")
+}
+
+// And back again, close enough for Showdown which ignores case and punctuation.
+func toDisplay(slug string) string {
+	words := strings.Split(slug, "-")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// Writes caught pokemon as a Showdown paste, sets separated by blank lines.
+func exportShowdown(w io.Writer, box []*Caught) error {
+	sets := make([]string, 0, len(box))
+	for _, c := range box {
+		sets = append(sets, showdownSet(c))
+	}
+	_, err := io.WriteString(w, strings.Join(sets, "\n"))
+	return err
+}
+
+func showdownSet(c *Caught) string {
+	var b strings.Builder
+
+	species := toDisplay(c.Pokemon.Name)
+	if c.Nickname != "" {
+		b.WriteString(c.Nickname + " (" + species + ")")
+	} else {
+		b.WriteString(species)
+	}
+	if c.Item != "" {
+		b.WriteString(" @ " + toDisplay(c.Item))
+	}
+	b.WriteString("\n")
+
+	if ability := cmp.Or(c.Ability, defaultAbility(c.Pokemon)); ability != "" {
+		fmt.Fprintf(&b, "Ability: %s\n", toDisplay(ability))
+	}
+	if c.Level > 0 && c.Level != 100 {
+		fmt.Fprintf(&b, "Level: %d\n", c.Level)
+	}
+	if c.Shiny {
+		b.WriteString("Shiny: Yes\n")
+	}
+	if evs := spreadLine(c.EVs, 0); evs != "" {
+		fmt.Fprintf(&b, "EVs: %s\n", evs)
+	}
+	if c.Nature != "" {
+		fmt.Fprintf(&b, "%s Nature\n", toDisplay(c.Nature))
+	}
+	if c.IVs != nil {
+		if ivs := spreadLine(*c.IVs, 31); ivs != "" {
+			fmt.Fprintf(&b, "IVs: %s\n", ivs)
+		}
+	}
+
+	moves := c.Moves
+	if len(moves) == 0 {
+		moves = defaultMoves(c.Pokemon)
+	}
+	for _, move := range moves {
+		fmt.Fprintf(&b, "- %s\n", toDisplay(move))
+	}
+	return b.String()
+}
+
+// "252 Atk / 4 SpD", leaving out stats at the Showdown default.
+func spreadLine(spread statSpread, skip int) string {
+	var parts []string
+	for _, stat := range showdownStats {
+		if v := *spread.Stat(stat.name); v != skip {
+			parts = append(parts, fmt.Sprintf("%d %s", v, stat.short))
+		}
+	}
+	return strings.Join(parts, " / ")
+}
+
+// First regular ability, what a wild catch would usually have.
+func defaultAbility(p *Pokemon) string {
+	for _, ability := range p.Abilities {
+		if !ability.IsHidden {
+			return ability.Ability.Name
+		}
+	}
+	return ""
+}
+
+// Up to four moves for pokemon without recorded ones: the latest it learns by level up.
+func defaultMoves(p *Pokemon) []string {
+	type learned struct {
+		name  string
+		level int
+	}
+	var moves []learned
+	for _, move := range p.Moves {
+		for _, detail := range move.VersionGroupDetails {
+			if detail.MoveLearnMethod.Name == "level-up" {
+				moves = append(moves, learned{move.Move.Name, detail.LevelLearnedAt})
+				break
+			}
+		}
+	}
+	sort.SliceStable(moves, func(i, j int) bool { return moves[i].level > moves[j].level })
+
+	var names []string
+	for i := 0; i < len(moves) && i < 4; i++ {
+		names = append(names, moves[i].name)
+	}
+	return names
+}
+
+// A set read from a paste, names are still as written.
+type showdownEntry struct {
+	line     int
+	nickname string
+	species  string
+	item     string
+	ability  string
+	level    int
+	shiny    bool
+	nature   string
+	evs      statSpread
+	ivs      statSpread
+	moves    []string
+}
+
+var (
+	nicknamePattern = regexp.MustCompile(`^(.*\S)\s+\(([^()]+)\)$`)
+	genderPattern   = regexp.MustCompile(`\s+\((M|F)\)$`)
+)
+
+// Reads every set in a Showdown paste. Lines it doesn't know, such as Tera Type, are skipped.
+func parseShowdown(text string) ([]showdownEntry, error) {
+	var entries []showdownEntry
+	var current *showdownEntry
+
+	for i, raw := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "===") {
+			current = nil
+			continue
+		}
+
+		if current == nil {
+			entries = append(entries, parseShowdownHeader(line, i+1))
+			current = &entries[len(entries)-1]
+			continue
+		}
+
+		key, value, hasKey := strings.Cut(line, ":")
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(line, "-"):
+			current.moves = append(current.moves, strings.TrimSpace(strings.TrimPrefix(line, "-")))
+		case strings.HasSuffix(line, " Nature"):
+			current.nature = strings.TrimSuffix(line, " Nature")
+		case hasKey && key == "Ability":
+			current.ability = value
+		case hasKey && key == "Shiny":
+			current.shiny = strings.EqualFold(value, "yes")
+		case hasKey && key == "Level":
+			level, err := strconv.Atoi(value)
+			if err != nil || level < 1 || level > 100 {
+				return nil, fmt.Errorf("line %d: level must be 1 to 100", i+1)
+			}
+			current.level = level
+		case hasKey && (key == "EVs" || key == "IVs"):
+			spread := &current.evs
+			if key == "IVs" {
+				spread = &current.ivs
+			}
+			if err := parseSpread(value, spread); err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+		}
+	}
+	return entries, nil
+}
+
+// "Sparky (Pikachu) (M) @ Light Ball" and its shorter forms.
+func parseShowdownHeader(line string, n int) showdownEntry {
+	entry := showdownEntry{line: n, level: 100, ivs: uniformSpread(31)}
+	name, item, _ := strings.Cut(line, " @ ")
+	entry.item = strings.TrimSpace(item)
+	name = genderPattern.ReplaceAllString(strings.TrimSpace(name), "")
+
+	if m := nicknamePattern.FindStringSubmatch(name); m != nil {
+		entry.nickname, entry.species = m[1], m[2]
+	} else {
+		entry.species = name
+	}
+	return entry
+}
+
+// "252 Atk / 4 SpD / 252 Spe" onto spread, leaving other stats alone.
+func parseSpread(value string, spread *statSpread) error {
+	for _, part := range strings.Split(value, "/") {
+		fields := strings.Fields(part)
+		if len(fields) != 2 {
+			return fmt.Errorf("can't read %q", strings.TrimSpace(part))
+		}
+		n, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return fmt.Errorf("can't read %q", strings.TrimSpace(part))
+		}
+		idx := slices.IndexFunc(showdownStats, func(s struct{ short, name string }) bool {
+			return strings.EqualFold(s.short, fields[1])
+		})
+		if idx < 0 {
+			return fmt.Errorf("unknown stat %q", fields[1])
+		}
+		*spread.Stat(showdownStats[idx].name) = n
+	}
+	return nil
+}
+
+// Checks a set against PokeAPI and turns it into a catch. Problems are returned, not the first one only.
+func (cfg *config) checkShowdownEntry(entry showdownEntry) (*Caught, []string) {
+	var problems []string
+	fail := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf("line %d: ", entry.line)+fmt.Sprintf(format, args...))
+	}
+
+	species := toSlug(entry.species)
+	data, err := fetch(cfg.cache, cfg.baseURL+"/pokemon/"+species)
+	if errors.Is(err, errNotFound) {
+		fail("unknown species %s", entry.species)
+		return nil, problems
+	}
+	if err != nil {
+		fail("%v", err)
+		return nil, problems
+	}
+	var mon *Pokemon
+	if err = json.Unmarshal(data, &mon); err != nil {
+		fail("%v", err)
+		return nil, problems
+	}
+
+	caught := &Caught{
+		Nickname: entry.nickname,
+		CaughtAt: time.Now(),
+		Level:    entry.level,
+		Shiny:    entry.shiny,
+		Item:     toSlug(entry.item),
+		EVs:      entry.evs,
+		Pokemon:  mon,
+	}
+	ivs := entry.ivs
+	caught.IVs = &ivs
+
+	if entry.ability != "" {
+		caught.Ability = toSlug(entry.ability)
+		known := false
+		for _, ability := range mon.Abilities {
+			known = known || ability.Ability.Name == caught.Ability
+		}
+		if !known {
+			fail("%s can't have the ability %s", mon.Name, entry.ability)
+		}
+	}
+
+	//Showdown's own default is a neutral nature
+	caught.Nature = "serious"
+	if entry.nature != "" {
+		caught.Nature = toSlug(entry.nature)
+		if _, known := natures[caught.Nature]; !known {
+			fail("unknown nature %s", entry.nature)
+		}
+	}
+
+	learnset := map[string]bool{}
+	for _, move := range mon.Moves {
+		learnset[move.Move.Name] = true
+	}
+	if len(entry.moves) > 4 {
+		fail("%s has more than four moves", mon.Name)
+	}
+	for _, move := range entry.moves {
+		slug := toSlug(move)
+		if !learnset[slug] {
+			fail("%s can't learn %s", mon.Name, move)
+		}
+		caught.Moves = append(caught.Moves, slug)
+	}
+
+	total := 0
+	for _, stat := range showdownStats {
+		ev, iv := *caught.EVs.Stat(stat.name), *caught.IVs.Stat(stat.name)
+		total += ev
+		if ev < 0 || ev > 252 {
+			fail("%s EVs must be 0 to 252", stat.short)
+		}
+		if iv < 0 || iv > 31 {
+			fail("%s IVs must be 0 to 31", stat.short)
+		}
+	}
+	if total > 510 {
+		fail("EVs add up to %d, the most is 510", total)
+	}
+	return caught, problems
+}
+
+// Reads pokemon from a Showdown paste into storage, nothing is added if any set is invalid
+func commandImport(cfg *config, args []string, _ cmdFlags) (any, error) {
+	if len(args) < 2 {
+		return nil, errUsage
+	}
+	if !strings.EqualFold(args[0], "showdown") {
+		return nil, fmt.Errorf("Can't import %q, only showdown", args[0])
+	}
+
+	data, err := os.ReadFile(args[1])
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parseShowdown(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("No pokemon found in %s", args[1])
+	}
+
+	var problems []string
+	var imported []*Caught
+	nicknames := map[string]bool{}
+	for _, entry := range entries {
+		caught, entryProblems := cfg.checkShowdownEntry(entry)
+		problems = append(problems, entryProblems...)
+		if caught == nil {
+			continue
+		}
+		if caught.Nickname != "" {
+			key := strings.ToLower(caught.Nickname)
+			if err := checkNickname(cfg.storage, 0, caught.Nickname); err != nil {
+				problems = append(problems, fmt.Sprintf("line %d: %v", entry.line, err))
+			} else if nicknames[key] {
+				problems = append(problems, fmt.Sprintf("line %d: the nickname %s is already taken in the file", entry.line, caught.Nickname))
+			}
+			nicknames[key] = true
+		}
+		imported = append(imported, caught)
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("Nothing imported, fix these first:\n  %s", strings.Join(problems, "\n  "))
+	}
+
+	result := importResult{Pokemon: []pokedexEntry{}}
+	for _, caught := range imported {
+		placeNew(cfg.storage, caught)
+		if err := cfg.storage.Add(caught); err != nil {
+			return nil, err
+		}
+		result.Pokemon = append(result.Pokemon, newPokedexEntry(caught))
+	}
+	return result, nil
+}
@@ -0,0 +1,12 @@
+package main
+
+import "github.com/Crimsonchamp/pokedexcli/storage"
+
+// The box and what it holds come from package storage, where backends outside
+// this module can reach them too.
+type (
+	Storage    = storage.Storage
+	Caught     = storage.Caught
+	Pokemon    = storage.Pokemon
+	statSpread = storage.StatSpread
+)
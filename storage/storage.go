@@ -0,0 +1,80 @@
+// Package storage is the box caught pokemon are kept in. The Pokedex only reaches
+// its catches through the Storage interface, so a backend from another module plugs
+// in by implementing it and calling Register from an init func.
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Storage keeps caught pokemon. Commands only go through this interface,
+// so a backend is free to keep the box wherever it likes.
+type Storage interface {
+	// Add stores a new catch and gives it the next free ID. IDs are never
+	// handed out twice, even after the catch holding one is removed.
+	Add(c *Caught) error
+	// Update replaces the stored catch with the same ID.
+	Update(c *Caught) error
+	// Restore puts back a catch that was removed, under its own ID.
+	Restore(c *Caught) error
+	// Remove deletes a catch, ErrNotCaught if there is none with that ID.
+	Remove(id int) error
+	// Get finds a catch by ID.
+	Get(id int) (*Caught, bool)
+	// List returns every catch ordered by ID.
+	List() []*Caught
+	// Query returns the catches match accepts, ordered by ID.
+	Query(match func(*Caught) bool) []*Caught
+	// Record reads the trainer record stored under key, such as stats, into v.
+	// Returns false and leaves v alone if there is none.
+	Record(key string, v any) (bool, error)
+	// SetRecord stores v as the trainer record under key.
+	SetRecord(key string, v any) error
+	// Close flushes anything pending and releases the backend.
+	Close() error
+}
+
+// Returned by Remove for an ID no catch has.
+var ErrNotCaught = errors.New("You have not caught this pokemon!")
+
+// Opens a storage backend, location is backend specific such as a file path.
+type Opener func(location string) (Storage, error)
+
+// Backends selectable with --storage, each registers itself from an init func.
+var backends = map[string]Opener{}
+
+// Makes a backend available under name, other backends plug in the same way
+// the built-in ones do, by calling this from their own file's init.
+func Register(name string, open Opener) {
+	if _, exists := backends[name]; exists {
+		panic("storage backend registered twice: " + name)
+	}
+	backends[name] = open
+}
+
+// Opens the named backend at location.
+func Open(name, location string) (Storage, error) {
+	open, exists := backends[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown storage backend %q, use one of: %s", name, strings.Join(Names(), ", "))
+	}
+	return open(location)
+}
+
+// Sorted names of the registered backends.
+func Names() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Orders catches by ID for List and Query, backends can share it.
+func SortByID(box []*Caught) {
+	sort.Slice(box, func(i, j int) bool { return box[i].ID < box[j].ID })
+}
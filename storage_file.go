@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+
+	"github.com/Crimsonchamp/pokedexcli/storage"
+)
+
+func init() {
+	storage.Register("file", openFileStorage)
+}
+
+// Storage backend that keeps the box in memory and autosaves it to a save file after every change.
+type fileStorage struct {
+	*memoryStorage
+	path string
+	// Set when the save is encrypted, nil saves get a checksum instead.
+	key *saveKey
+	// How the save was last sealed, see sealMarker
+	seal string
+	// Set while the file is behind, after a failed save or when it has to be sealed again
+	unsaved bool
+}
+
+// Loads the save file at path, a missing file just means nothing was caught yet.
+func openFileStorage(path string) (Storage, error) {
+	if path == "" {
+		return nil, errors.New("file storage needs a save file")
+	}
+	f := &fileStorage{memoryStorage: newMemoryStorage(), path: path}
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *fileStorage) Add(c *Caught) error {
+	if err := f.memoryStorage.Add(c); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+func (f *fileStorage) Update(c *Caught) error {
+	if err := f.memoryStorage.Update(c); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+func (f *fileStorage) Restore(c *Caught) error {
+	if err := f.memoryStorage.Restore(c); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+func (f *fileStorage) Remove(id int) error {
+	if err := f.memoryStorage.Remove(id); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+func (f *fileStorage) SetRecord(key string, v any) error {
+	if err := f.memoryStorage.SetRecord(key, v); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// Every change is saved as it happens, closing only retries a save that failed.
+func (f *fileStorage) Close() error {
+	if !f.unsaved {
+		return nil
+	}
+	return f.save()
+}
+
+// Checks the save's checksum, or decrypts it. Older save files are migrated
+// up to the current version, keeping a backup of the original.
+func (f *fileStorage) load() error {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	original := data
+	if data, f.key, err = unsealSave(f.path, data); err != nil {
+		return fmt.Errorf("%s %w", f.path, err)
+	}
+	version, err := saveVersionOf(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.path, err)
+	}
+
+	//A save that was sealed before only loads unsealed when the trainer says so,
+	//and one that was encrypted stays encrypted until 'save decrypt'
+	if f.seal, err = sealMarker(f.path); err != nil {
+		return err
+	}
+	switch {
+	case f.seal == sealEncrypted && f.key == nil:
+		if !acceptUnsealed {
+			return fmt.Errorf("%s %w", f.path, errUnencrypted)
+		}
+		passphrase, err := askPassphrase(fmt.Sprintf("New passphrase for %s:", f.path))
+		if err != nil {
+			return err
+		}
+		if f.key, err = newSaveKey(passphrase); err != nil {
+			return err
+		}
+		f.unsaved = true
+	case f.seal != "" && version < sealedSince:
+		if !acceptUnsealed {
+			return fmt.Errorf("%s %w", f.path, errUnsealed)
+		}
+		f.unsaved = true
+	case version < sealedSince:
+		note(fmt.Sprintf("%s is from before save files had checksums, it gets one when saved", f.path))
+	}
+	//A migrated save is written back on close, so what it was given on the way up sticks
+	if version < saveVersion {
+		if err = backupSave(f.path, original, version); err != nil {
+			return err
+		}
+		f.unsaved = true
+	}
+	save, _, err := decodeSave(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.path, err)
+	}
+
+	for _, c := range save.Box {
+		if err = f.memoryStorage.Restore(c); err != nil {
+			return fmt.Errorf("%s: %w", f.path, err)
+		}
+	}
+	f.nextID = max(f.nextID, save.NextID)
+	for key, record := range save.Records {
+		f.records[key] = record
+	}
+	return nil
+}
+
+func (f *fileStorage) save() (err error) {
+	defer func() { f.unsaved = err != nil }()
+	f.mu.Lock()
+	records := maps.Clone(f.records)
+	nextID := f.nextID
+	f.mu.Unlock()
+
+	data, err := json.Marshal(saveFile{Version: saveVersion, NextID: nextID, Box: f.List(), Records: records})
+	if err != nil {
+		return err
+	}
+	if data, err = sealSave(data, f.key); err != nil {
+		return err
+	}
+	if err = writeFileAtomic(f.path, data); err != nil {
+		return err
+	}
+	seal := sealChecksum
+	if f.key != nil {
+		seal = sealEncrypted
+	}
+	if seal != f.seal {
+		if err = setSealMarker(f.path, seal); err != nil {
+			return err
+		}
+		f.seal = seal
+	}
+	return nil
+}
+
+// Encrypts the save from now on, or stops encrypting it with a nil key.
+func (f *fileStorage) setKey(key *saveKey) error {
+	f.key = key
+	return f.save()
+}
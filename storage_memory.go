@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/Crimsonchamp/pokedexcli/storage"
+)
+
+func init() {
+	storage.Register("memory", func(string) (Storage, error) {
+		return newMemoryStorage(), nil
+	})
+}
+
+// Storage backend that only lives as long as the session. Catches are copied
+// in and out, so callers change them through Update only.
+type memoryStorage struct {
+	mu      sync.Mutex
+	box     map[int]*Caught
+	nextID  int
+	records map[string]json.RawMessage
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{
+		box:     make(map[int]*Caught),
+		nextID:  1,
+		records: make(map[string]json.RawMessage),
+	}
+}
+
+func (m *memoryStorage) Add(c *Caught) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c.ID = m.nextID
+	m.nextID++
+	stored := *c
+	m.box[c.ID] = &stored
+	return nil
+}
+
+func (m *memoryStorage) Restore(c *Caught) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.box[c.ID]; exists {
+		return fmt.Errorf("there is already a catch with ID %d", c.ID)
+	}
+	stored := *c
+	m.box[c.ID] = &stored
+	m.nextID = max(m.nextID, c.ID+1)
+	return nil
+}
+
+func (m *memoryStorage) Update(c *Caught) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.box[c.ID]; !exists {
+		return errNotCaught
+	}
+	stored := *c
+	m.box[c.ID] = &stored
+	return nil
+}
+
+func (m *memoryStorage) Remove(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.box[id]; !exists {
+		return errNotCaught
+	}
+	delete(m.box, id)
+	return nil
+}
+
+func (m *memoryStorage) Get(id int) (*Caught, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, exists := m.box[id]
+	if !exists {
+		return nil, false
+	}
+	out := *c
+	return &out, true
+}
+
+func (m *memoryStorage) List() []*Caught {
+	return m.Query(func(*Caught) bool { return true })
+}
+
+func (m *memoryStorage) Query(match func(*Caught) bool) []*Caught {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*Caught
+	for _, c := range m.box {
+		if match(c) {
+			copied := *c
+			out = append(out, &copied)
+		}
+	}
+	storage.SortByID(out)
+	return out
+}
+
+// Records are kept as JSON so callers never share memory with the store.
+func (m *memoryStorage) Record(key string, v any) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, exists := m.records[key]
+	if !exists {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+func (m *memoryStorage) SetRecord(key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records[key] = data
+	return nil
+}
+
+func (m *memoryStorage) Close() error {
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/Crimsonchamp/pokedexcli/internal/pokecache"
+	"github.com/Crimsonchamp/pokedexcli/internal/suggest"
+)
+
+var errNotFound = errors.New("not found")
+
+// Gets url through the cache, only successful responses are cached.
+func fetch(cache *pokecache.Cache, url string) ([]byte, error) {
+	if data, found := cache.Get(url); found {
+		note("\nUsing Cached Data")
+		return data, nil
+	}
+
+	note("\nFetching New Data")
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned non-OK status: %v", resp.Status)
+	}
+
+	cache.Add(url, data)
+	return data, nil
+}
+
+// Lists every name of a PokeAPI resource such as "pokemon" or "location-area".
+// Resource lists share the same shape as Location, so it is reused here.
+func resourceNames(cfg *config, resource string) ([]string, error) {
+	data, err := fetch(cfg.cache, cfg.baseURL+"/"+resource+"?limit=100000")
+	if err != nil {
+		return nil, err
+	}
+
+	var list Location
+	if err = json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(list.Results))
+	for _, result := range list.Results {
+		names = append(names, result.Name)
+	}
+	return names, nil
+}
+
+// Prints the closest matches to query and asks whether to run the top one, run is a format like "catch %s".
+// Returns false if nothing is close or the user declined.
+func offerSuggestion(query string, candidates []string, run string) (string, bool) {
+	matches := suggest.Closest(query, candidates, 3)
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	note("Did you mean:")
+	for _, match := range matches {
+		note(" - " + match)
+	}
+	return matches[0], confirm(fmt.Sprintf("Run '"+run+"'?", matches[0]))
+}
+
+// Asks a yes/no question on the prompt, anything but y/yes counts as no.
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s (y/n) ", question)
+	line, ok := readAnswer()
+	if !ok {
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
+
+// Suggests area names for a failed explore or goto, running the accepted one with the original flags.
+// Falls back to the typo hint if none is accepted.
+func suggestArea(cfg *config, query, command string, flags cmdFlags, callback func(*config, []string, cmdFlags) (any, error)) (any, error) {
+	areas, err := resourceNames(cfg, "location-area")
+	if err != nil {
+		return nil, errTypo
+	}
+	if area, ok := offerSuggestion(query, areas, command+" %s"); ok {
+		return callback(cfg, []string{area}, flags)
+	}
+	return nil, errTypo
+}
+
+// Suggests pokemon of the current area for a failed catch, only those can be caught there.
+func suggestPokemon(cfg *config, query string, local []string, flags cmdFlags) (any, error) {
+	if name, ok := offerSuggestion(query, local, "catch %s"); ok {
+		return commandCatch(cfg, []string{name}, flags)
+	}
+	return nil, fmt.Errorf("There are no wild %s in %s, see 'explore %s'", query, cfg.currentArea, cfg.currentArea)
+}
+
+// Suggests command names for unknown input, running the accepted one with the original arguments.
+func suggestCommand(cfg *config, commands map[string]cliCommand, words []string) (any, error) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	run := strings.ReplaceAll(strings.Join(words[1:], " "), "%", "%%")
+	match, ok := offerSuggestion(words[0], names, strings.TrimSpace("%s "+run))
+	if !ok {
+		return nil, errUnknownCommand
+	}
+	return runCommand(cfg, commands, append([]string{match}, words[1:]...))
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Trainer record holding the undo history
+const undoRecord = "undo"
+
+// How many commands undo can go back
+const undoLimit = 10
+
+// Records that aren't about the trainer's pokemon, so undo leaves them alone
+var unjournaled = []string{undoRecord, locationRecord, settingsRecord}
+
+// Records undo only puts back along with a catch the same command changed, so a
+// missed throw can't be undone to get the ball back and forget the throw
+var withCatches = []string{bagRecord, statsRecord}
+
+// What one command changed, enough to put storage back the way it was.
+type undoStep struct {
+	Command string    `json:"command"`
+	At      time.Time `json:"at"`
+	// IDs the command added, undo removes them again.
+	Added []int `json:"added,omitempty"`
+	// Catches as they were before the command changed or removed them.
+	Before []*Caught `json:"before,omitempty"`
+	// Records as they were before, null for ones that didn't exist yet.
+	Records map[string]json.RawMessage `json:"records,omitempty"`
+}
+
+func (s *undoStep) empty() bool {
+	return len(s.Added) == 0 && len(s.Before) == 0 && len(s.Records) == 0
+}
+
+// Storage wrapper that notes what each command changes, so undo can take it back.
+// Changes outside of a command, such as tidying up on load, are not noted.
+type journal struct {
+	Storage
+	step *undoStep
+}
+
+func newJournal(s Storage) *journal {
+	return &journal{Storage: s}
+}
+
+// Starts noting changes for a command line.
+func (j *journal) begin(words []string) {
+	j.step = &undoStep{Command: strings.Join(words, " "), At: time.Now(), Records: map[string]json.RawMessage{}}
+}
+
+// Stops noting changes and adds them to the undo history if there were any.
+func (j *journal) end() error {
+	step := j.step
+	j.step = nil
+	if step != nil && len(step.Added) == 0 && len(step.Before) == 0 {
+		for _, key := range withCatches {
+			delete(step.Records, key)
+		}
+	}
+	if step == nil || step.empty() {
+		return nil
+	}
+
+	var history []undoStep
+	if _, err := j.Storage.Record(undoRecord, &history); err != nil {
+		return err
+	}
+	history = append(history, *step)
+	if len(history) > undoLimit {
+		history = history[len(history)-undoLimit:]
+	}
+	return j.Storage.SetRecord(undoRecord, history)
+}
+
+// Keeps the first state of a catch seen during the step, unless the step added it.
+func (j *journal) noteCatch(id int) {
+	if j.step == nil || slices.Contains(j.step.Added, id) {
+		return
+	}
+	if slices.ContainsFunc(j.step.Before, func(c *Caught) bool { return c.ID == id }) {
+		return
+	}
+	if c, exists := j.Storage.Get(id); exists {
+		j.step.Before = append(j.step.Before, c)
+	}
+}
+
+func (j *journal) Add(c *Caught) error {
+	if err := j.Storage.Add(c); err != nil {
+		return err
+	}
+	if j.step != nil {
+		j.step.Added = append(j.step.Added, c.ID)
+	}
+	return nil
+}
+
+func (j *journal) Restore(c *Caught) error {
+	if err := j.Storage.Restore(c); err != nil {
+		return err
+	}
+	if j.step != nil {
+		j.step.Added = append(j.step.Added, c.ID)
+	}
+	return nil
+}
+
+func (j *journal) Update(c *Caught) error {
+	j.noteCatch(c.ID)
+	return j.Storage.Update(c)
+}
+
+func (j *journal) Remove(id int) error {
+	j.noteCatch(id)
+	return j.Storage.Remove(id)
+}
+
+func (j *journal) SetRecord(key string, v any) error {
+	if j.step != nil && !slices.Contains(unjournaled, key) {
+		if _, noted := j.step.Records[key]; !noted {
+			before := json.RawMessage("null")
+			if _, err := j.Storage.Record(key, &before); err != nil {
+				return err
+			}
+			j.step.Records[key] = before
+		}
+	}
+	return j.Storage.SetRecord(key, v)
+}
+
+// Takes back the latest step in the history. The step undo itself runs in is dropped,
+// undoing is not something to undo.
+func (j *journal) undo() (*undoStep, error) {
+	j.step = nil
+
+	var history []undoStep
+	if _, err := j.Storage.Record(undoRecord, &history); err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, errors.New("Nothing to undo!")
+	}
+	step := history[len(history)-1]
+
+	for _, id := range step.Added {
+		if _, exists := j.Storage.Get(id); exists {
+			if err := j.Storage.Remove(id); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, c := range step.Before {
+		var err error
+		if _, exists := j.Storage.Get(c.ID); exists {
+			err = j.Storage.Update(c)
+		} else {
+			err = j.Storage.Restore(c)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	for key, before := range step.Records {
+		if err := j.Storage.SetRecord(key, before); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := j.Storage.SetRecord(undoRecord, history[:len(history)-1]); err != nil {
+		return nil, err
+	}
+	return &step, nil
+}
+
+// Takes back the last command that changed storage
+func commandUndo(cfg *config, _ []string, _ cmdFlags) (any, error) {
+	step, err := cfg.history.undo()
+	if err != nil {
+		return nil, err
+	}
+	return undoResult{Command: step.Command, At: step.At}, nil
+}
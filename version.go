@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Game versions with encounters in an area, in the order they first show up.
+func areaVersions(area *Area) []string {
+	var versions []string
+	for _, slot := range encounterSlots(area, "") {
+		if !slices.Contains(versions, slot.Version) {
+			versions = append(versions, slot.Version)
+		}
+	}
+	return versions
+}
+
+// Pokemon of an area that only some of its versions have, with those versions.
+func exclusivePokemon(area *Area) map[string][]string {
+	versions := areaVersions(area)
+	in := map[string][]string{}
+	for _, slot := range encounterSlots(area, "") {
+		if !slices.Contains(in[slot.Pokemon], slot.Version) {
+			in[slot.Pokemon] = append(in[slot.Pokemon], slot.Version)
+		}
+	}
+	exclusive := map[string][]string{}
+	for pokemon, pokemonVersions := range in {
+		if len(pokemonVersions) < len(versions) {
+			exclusive[pokemon] = pokemonVersions
+		}
+	}
+	return exclusive
+}
+
+// Checks PokeAPI knows a game version.
+func checkGameVersion(cfg *config, version string) error {
+	_, err := fetch(cfg.cache, cfg.baseURL+"/version/"+version)
+	if errors.Is(err, errNotFound) {
+		return fmt.Errorf("There is no game version called %s", version)
+	}
+	if err != nil {
+		return fmt.Errorf("Error Fetching URL: %w", err)
+	}
+	return nil
+}
+
+// Picks the game version wild pokemon come from for the rest of the session, or shows it
+func commandVersion(cfg *config, args []string, _ cmdFlags) (any, error) {
+	if len(args) > 0 {
+		version := toSlug(args[0])
+		if version == "all" {
+			version = ""
+		} else if err := checkGameVersion(cfg, version); err != nil {
+			return nil, err
+		}
+		cfg.gameVersion = version
+	}
+
+	result := versionResult{Version: cfg.gameVersion, Area: cfg.currentArea}
+	if cfg.currentArea != "" {
+		area, err := fetchArea(cfg, cfg.currentArea)
+		if err != nil {
+			return nil, fmt.Errorf("Get Error: %w", err)
+		}
+		result.AreaVersions = areaVersions(area)
+	}
+	return result, nil
+}